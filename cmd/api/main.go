@@ -7,9 +7,13 @@ import (
 	"konnect/internal/config"
 	"konnect/internal/database"
 	"konnect/internal/handler"
+	"konnect/internal/health"
 	"konnect/internal/logger"
+	"konnect/internal/metrics"
 	"konnect/internal/router"
 	"konnect/internal/service"
+	"konnect/internal/template"
+	"konnect/internal/tracing"
 	"konnect/internal/worker"
 	"log"
 	"net/http"
@@ -20,6 +24,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/providers/google"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
@@ -50,11 +55,27 @@ func main() {
 		logger.Fatal("failed to load config", zap.Error(err))
 	}
 
+	// tracing
+	shutdownTracing, err := tracing.Init(context.Background(), cfg, "konnect-api")
+	if err != nil {
+		logger.Fatal("failed to initialize tracing", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("failed to flush traces", zap.Error(err))
+		}
+	}()
+
 	// db
 	db, err := database.New(cfg, logger)
 	if err != nil {
 		logger.Fatal("failed to connect to database", zap.String("component", "main"), zap.Error(err))
 	}
+	if err := metrics.RegisterDB(db.DB); err != nil {
+		logger.Fatal("failed to register database metrics", zap.Error(err))
+	}
 
 	// worker client
 	workerClient := worker.NewWorkerClient(cfg)
@@ -77,32 +98,78 @@ func main() {
 		logger.Fatal("failed to initialize cloudinary service", zap.String("component", "main"), zap.Error(err))
 	}
 
+	// email templates
+	emailTemplates, err := template.NewRegistry()
+	if err != nil {
+		logger.Fatal("failed to load email templates", zap.Error(err))
+	}
+
 	// cache services
 	interestCache := cache.NewInterests(cacheClient, logger)
+	otpCache := cache.NewOTP(cacheClient, logger)
+	syncStats := cache.NewSyncStats(cacheClient, logger)
+	geoCache := cache.NewGeo(cacheClient, logger)
+	boostCache := cache.NewBoosts(cacheClient, logger)
+	impressionCache := cache.NewImpressions(cacheClient, logger)
 
 	// services
 	authService := service.NewAuthService(db, cfg, logger)
-	// profile service now depends on the interest cache
-	profileService := service.NewProfileService(db, interestCache, logger)
-	swipeService := service.NewSwipeService(db, workerClient.Client, logger)
+	boostService := service.NewBoostService(db, boostCache, cfg, logger)
+	impressionService := service.NewImpressionService(db, impressionCache, logger)
+	// profile service syncs the interest cache and location index through the worker
+	profileService := service.NewProfileService(db, workerClient.Client, syncStats, geoCache, interestCache, boostService, impressionService, logger)
+	paystackService := service.NewPaystackService(cfg, logger)
+	billingService := service.NewBillingService(db, workerClient.Client, paystackService, boostService, cfg, logger)
+	webhookService := service.NewWebhookService(db, workerClient.Client, paystackService, billingService, logger)
+	swipeService := service.NewSwipeService(db, workerClient.Client, billingService, logger)
+	notificationService := service.NewNotificationService(db, logger)
+	phoneVerificationService := service.NewPhoneVerificationService(db, cfg, otpCache, emailTemplates, workerClient.Client, logger)
+	deviceService := service.NewDeviceService(db, logger)
+	preferenceService := service.NewNotificationPreferenceService(db, cfg, logger)
+	interestService := service.NewInterestService(db, interestCache, logger)
 
 	// handlers
 	authHandler := handler.NewAuthHandler(authService)
-	profileHandler := handler.NewProfileHandler(profileService, cloudinaryService, logger)
+	userHandler := handler.NewUserHandler(authService, phoneVerificationService, logger)
+	profileHandler := handler.NewProfileHandler(profileService, cloudinaryService, interestService, impressionService, logger)
 	swipeHandler := handler.NewSwipeHandler(swipeService, logger)
+	notificationHandler := handler.NewNotificationHandler(notificationService, preferenceService, logger)
+	deviceHandler := handler.NewDeviceHandler(deviceService, logger)
+	emailTemplateHandler := handler.NewEmailTemplateHandler(emailTemplates, logger)
+	adminHandler := handler.NewAdminHandler(syncStats, logger)
+	interestHandler := handler.NewInterestHandler(interestService, logger)
+	billingHandler := handler.NewBillingHandler(billingService, boostService, logger)
+	webhookHandler := handler.NewWebhookHandler(webhookService, logger)
+
+	// readiness checks, paystack is optional as only billing depends on it
+	healthComponents := []health.Component{
+		health.Postgres(db.DB),
+		health.Redis(cacheClient.Client),
+		health.Broker(workerClient.Client),
+	}
+	if cfg.PaystackSecret != "" {
+		paystackHealth, err := health.DialURL("paystack", cfg.PaystackBaseURL, true)
+		if err != nil {
+			logger.Fatal("invalid paystack base url", zap.Error(err))
+		}
+		healthComponents = append(healthComponents, paystackHealth)
+	} else {
+		logger.Warn("PAYSTACK_SECRET_KEY is not set, billing is unavailable and paystack webhooks are rejected")
+	}
+	healthHandler := handler.NewHealthHandler(health.NewChecker(health.DefaultTimeout, healthComponents...), logger)
 
 	// middleware
 	middleware := handler.NewMiddleware(authService, logger)
 
-	// enqueue seeder jobs
-	if err := worker.NewCacheSeedingJob(workerClient.Client); err != nil {
-		logger.Fatal("failed to enqueue cache seeding job", zap.Error(err))
-	}
-
 	// server router
-	r := gin.Default()
+	// requests are logged with zap by the router middleware
+	r := gin.New()
+	// client ips are checked against the paystack webhook origins
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Fatal("invalid trusted proxies", zap.Error(err))
+	}
 
-	router.RegisterRoutes(r, middleware, authHandler, profileHandler, swipeHandler)
+	router.RegisterRoutes(r, middleware, authHandler, profileHandler, swipeHandler, notificationHandler, deviceHandler, userHandler, emailTemplateHandler, adminHandler, interestHandler, billingHandler, webhookHandler, healthHandler)
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%v", cfg.Port),
@@ -112,6 +179,22 @@ func main() {
 		WriteTimeout: 30 * time.Second,
 	}
 
+	// prometheus scrapes on their own port, so route names, latencies and pool stats stay private
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("GET /metrics", promhttp.Handler())
+	metricsServer := &http.Server{
+		Addr:              fmt.Sprintf(":%v", cfg.MetricsPort),
+		Handler:           metricsMux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.Info("Metrics server starting", zap.String("addr", metricsServer.Addr))
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("metrics server error", zap.Error(err))
+		}
+	}()
+	defer metricsServer.Close()
+
 	done := make(chan struct{}, 1)
 	go gracefulShutdown(server, logger, done)
 
@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"konnect/internal/cache"
 	"konnect/internal/config"
 	"konnect/internal/database"
+	"konnect/internal/health"
 	"konnect/internal/logger"
+	"konnect/internal/metrics"
 	"konnect/internal/service"
+	"konnect/internal/template"
+	"konnect/internal/tracing"
 	"konnect/internal/worker"
 	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
@@ -28,11 +40,27 @@ func main() {
 		logger.Fatal("failed to load config", zap.Error(err))
 	}
 
+	// tracing
+	shutdownTracing, err := tracing.Init(context.Background(), cfg, "konnect-worker")
+	if err != nil {
+		logger.Fatal("failed to initialize tracing", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("failed to flush traces", zap.Error(err))
+		}
+	}()
+
 	// db
 	db, err := database.New(cfg, logger)
 	if err != nil {
 		logger.Fatal("failed to connect to database", zap.String("component", "main"), zap.Error(err))
 	}
+	if err := metrics.RegisterDB(db.DB); err != nil {
+		logger.Fatal("failed to register database metrics", zap.Error(err))
+	}
 
 	// cache
 	cacheClient, err := cache.New(cfg)
@@ -41,6 +69,10 @@ func main() {
 	}
 	defer cacheClient.Close()
 
+	// client used by processors to defer tasks
+	workerClient := worker.NewWorkerClient(cfg)
+	defer workerClient.Close()
+
 	srv := asynq.NewServer(
 		asynq.RedisClientOpt{Addr: cfg.RedisAddr, Password: cfg.RedisPassword},
 		asynq.Config{
@@ -51,21 +83,134 @@ func main() {
 		},
 	)
 
-	// handlers and services
-	emailService := service.NewEmailService(cfg)
+	// email templates
+	emailTemplates, err := template.NewRegistry()
+	if err != nil {
+		logger.Fatal("failed to load email templates", zap.Error(err))
+	}
 
 	// cache services
 	interestCache := cache.NewInterests(cacheClient, logger)
+	feedCache := cache.NewFeeds(cacheClient, logger)
+	geoCache := cache.NewGeo(cacheClient, logger)
+	syncStats := cache.NewSyncStats(cacheClient, logger)
+	boostCache := cache.NewBoosts(cacheClient, logger)
+	impressionCache := cache.NewImpressions(cacheClient, logger)
+
+	// handlers and services
+	emailDispatcher, err := service.NewEmailDispatcher(cfg, logger)
+	if err != nil {
+		logger.Fatal("failed to initialize email transport", zap.Error(err))
+	}
+	notificationService := service.NewNotificationService(db, logger)
+	deviceService := service.NewDeviceService(db, logger)
+	preferenceService := service.NewNotificationPreferenceService(db, cfg, logger)
+	digestService := service.NewDigestService(db, workerClient.Client, logger)
+	paystackService := service.NewPaystackService(cfg, logger)
+	boostService := service.NewBoostService(db, boostCache, cfg, logger)
+	impressionService := service.NewImpressionService(db, impressionCache, logger)
+	billingService := service.NewBillingService(db, workerClient.Client, paystackService, boostService, cfg, logger)
+	webhookService := service.NewWebhookService(db, workerClient.Client, paystackService, billingService, logger)
+	pushDispatcher, err := service.NewPushDispatcher(cfg, logger)
+	if err != nil {
+		logger.Fatal("failed to initialize push dispatcher", zap.Error(err))
+	}
+
+	smsDispatcher, err := service.NewSMSDispatcher(cfg, logger)
+	if err != nil {
+		logger.Fatal("failed to initialize sms dispatcher", zap.Error(err))
+	}
 
-	emailProcessor := worker.NewEmailProcessor(emailService)
+	emailProcessor := worker.NewEmailProcessor(emailDispatcher, emailTemplates, preferenceService, workerClient.Client)
 	cacheSeederProcessor := worker.NewCacheSeederProcessor(db, interestCache, logger)
-	// smsProcessor := worker.NewSMSProcessor()
+	geoSeederProcessor := worker.NewGeoSeederProcessor(db, geoCache, logger)
+	inAppProcessor := worker.NewInAppProcessor(notificationService)
+	pushProcessor := worker.NewPushProcessor(pushDispatcher, deviceService, preferenceService, workerClient.Client)
+	smsProcessor := worker.NewSMSProcessor(smsDispatcher)
+	digestProcessor := worker.NewDigestProcessor(digestService)
+	pruneInterestsProcessor := worker.NewPruneInterestsProcessor(db, interestCache, logger)
+	expireFeedsProcessor := worker.NewExpireFeedsProcessor(feedCache, logger)
+	webhookEventProcessor := worker.NewWebhookEventProcessor(webhookService)
+	paymentVerificationProcessor := worker.NewPaymentVerificationProcessor(billingService, workerClient.Client)
+	expireBoostsProcessor := worker.NewExpireBoostsProcessor(boostService)
+	flushImpressionsProcessor := worker.NewFlushImpressionsProcessor(impressionService)
+	profileSyncProcessor := worker.NewProfileSyncProcessor(db, interestCache, geoCache, syncStats, logger)
 
 	// mux maps a type to a handler
 	mux := asynq.NewServeMux()
+	mux.Use(worker.TracingMiddleware(), worker.LoggingMiddleware(logger), worker.MetricsMiddleware())
 	mux.Handle(worker.TypeEmailDelivery, emailProcessor)
 	mux.Handle(worker.TypeSeedCache, cacheSeederProcessor)
-	// mux.Handle(worker.TypeSMSDelivery, smsProcessor)
+	mux.Handle(worker.TypeSeedGeo, geoSeederProcessor)
+	mux.Handle(worker.TypeInAppDelivery, inAppProcessor)
+	mux.Handle(worker.TypePushDelivery, pushProcessor)
+	mux.Handle(worker.TypeSMSDelivery, smsProcessor)
+	mux.Handle(worker.TypeDigestEmail, digestProcessor)
+	mux.Handle(worker.TypePruneInterests, pruneInterestsProcessor)
+	mux.Handle(worker.TypeExpireFeeds, expireFeedsProcessor)
+	mux.Handle(worker.TypeSyncProfile, profileSyncProcessor)
+	mux.Handle(worker.TypeWebhookEvent, webhookEventProcessor)
+	mux.Handle(worker.TypeVerifyPayment, paymentVerificationProcessor)
+	mux.Handle(worker.TypeExpireBoosts, expireBoostsProcessor)
+	mux.Handle(worker.TypeFlushImpressions, flushImpressionsProcessor)
+
+	// periodic jobs
+	periodicTasks, err := worker.PeriodicTasks(cfg)
+	if err != nil {
+		logger.Fatal("failed to create periodic tasks", zap.Error(err))
+	}
+	scheduler, err := worker.NewScheduler(cfg, periodicTasks)
+	if err != nil {
+		logger.Fatal("failed to register periodic tasks", zap.Error(err))
+	}
+	if err := scheduler.Start(); err != nil {
+		logger.Fatal("could not start scheduler", zap.Error(err))
+	}
+	defer scheduler.Shutdown()
+
+	// seed a fresh cache without waiting for the first scheduled run
+	if err := worker.NewCacheSeedingJob(workerClient.Client); err != nil {
+		logger.Fatal("failed to enqueue cache seeding job", zap.Error(err))
+	}
+	if err := worker.NewGeoSeedingJob(workerClient.Client); err != nil {
+		logger.Fatal("failed to enqueue geo seeding job", zap.Error(err))
+	}
+
+	// queue depth and task metrics for prometheus
+	queueCollector := worker.NewQueueCollector(cfg)
+	defer queueCollector.Close()
+	if err := prometheus.Register(queueCollector); err != nil {
+		logger.Fatal("failed to register queue metrics", zap.Error(err))
+	}
+
+	// readiness checks, smtp is optional as emails are retried until it is back
+	healthComponents := []health.Component{
+		health.Postgres(db.DB),
+		health.Redis(cacheClient.Client),
+		health.Broker(srv),
+	}
+	if cfg.EmailTransport == "smtp" {
+		healthComponents = append(healthComponents, health.Dial("smtp", net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)), true))
+	}
+	healthChecker := health.NewChecker(health.DefaultTimeout, healthComponents...)
+
+	// metrics and health probes
+	opsMux := http.NewServeMux()
+	opsMux.Handle("GET /metrics", promhttp.Handler())
+	opsMux.Handle("GET /health/live", health.LiveHandler())
+	opsMux.Handle("GET /health/ready", healthChecker.ReadyHandler())
+	opsServer := &http.Server{
+		Addr:              fmt.Sprintf(":%v", cfg.WorkerPort),
+		Handler:           opsMux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.Info("Metrics and health server starting", zap.String("addr", opsServer.Addr))
+		if err := opsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("metrics and health server error", zap.Error(err))
+		}
+	}()
+	defer opsServer.Close()
 
 	if err := srv.Run(mux); err != nil {
 		logger.Fatal("could not run server", zap.Error(err))
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"konnect/internal/logger"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// BoostViewsTTL bounds how long the view counter and the schedule of a boost outlive its end when the expiry job does
+// not run
+const BoostViewsTTL = 7 * 24 * time.Hour
+
+type BoostCache struct {
+	client *Client
+	logger *zap.Logger
+}
+
+func NewBoosts(client *Client, logger *logger.Logger) *BoostCache {
+	return &BoostCache{
+		client: client,
+		logger: logger.With(zap.String("component", "boost_cache")),
+	}
+}
+
+// SetBoost marks a user as boosted until the end of a boost and adds the boost to the user's schedule. A later end of
+// the user's boosts is kept
+func (b *BoostCache) SetBoost(ctx context.Context, userID, boostID string, endsAt time.Time) error {
+	tx := b.client.TxPipeline()
+	tx.ZAddArgs(ctx, GetActiveBoostsKey(), redis.ZAddArgs{
+		GT:      true,
+		Members: []redis.Z{{Score: float64(endsAt.Unix()), Member: userID}},
+	})
+	tx.ZAdd(ctx, GetUserBoostsKey(userID), redis.Z{Score: float64(endsAt.Unix()), Member: boostID})
+	tx.Expire(ctx, GetUserBoostsKey(userID), time.Until(endsAt)+BoostViewsTTL)
+	_, err := tx.Exec(ctx)
+	return err
+}
+
+// GetBoosted returns the users boosted at t
+func (b *BoostCache) GetBoosted(ctx context.Context, t time.Time) ([]string, error) {
+	return b.client.ZRangeByScore(ctx, GetActiveBoostsKey(), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(t.Unix(), 10),
+		Max: "+inf",
+	}).Result()
+}
+
+// RemoveEnded drops the users whose boosts ended before t
+func (b *BoostCache) RemoveEnded(ctx context.Context, t time.Time) error {
+	return b.client.ZRemRangeByScore(ctx, GetActiveBoostsKey(), "-inf", strconv.FormatInt(t.Unix(), 10)).Err()
+}
+
+// RecordViews counts a view for the boost running at t of each of the users. Boosts of a user run back to back, the
+// running one is the first of their schedule that ends after t
+func (b *BoostCache) RecordViews(ctx context.Context, userIDs []string, t time.Time) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	ends, err := b.client.ZMScore(ctx, GetActiveBoostsKey(), userIDs...).Result()
+	if err != nil {
+		return err
+	}
+
+	after := "(" + strconv.FormatInt(t.Unix(), 10)
+	pipe := b.client.Pipeline()
+	var running []*redis.StringSliceCmd
+	for i, userID := range userIDs {
+		// missing members score 0
+		if ends[i] <= float64(t.Unix()) {
+			continue
+		}
+		running = append(running, pipe.ZRangeArgs(ctx, redis.ZRangeArgs{
+			Key:     GetUserBoostsKey(userID),
+			Start:   after,
+			Stop:    "+inf",
+			ByScore: true,
+			Count:   1,
+		}))
+	}
+	if len(running) == 0 {
+		return nil
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	pipe = b.client.Pipeline()
+	for _, cmd := range running {
+		// boosts missing from the schedule are added back by the expiry job
+		if len(cmd.Val()) == 0 {
+			continue
+		}
+		boostID := cmd.Val()[0]
+		pipe.Incr(ctx, GetBoostViewsKey(boostID))
+		pipe.Expire(ctx, GetBoostViewsKey(boostID), BoostViewsTTL)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetViews returns the views of a boost so far
+func (b *BoostCache) GetViews(ctx context.Context, boostID string) (int64, error) {
+	views, err := b.client.Get(ctx, GetBoostViewsKey(boostID)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return views, err
+}
+
+// TakeViews returns the views of a boost once it ended, and drops its counter and its entry in the user's schedule
+func (b *BoostCache) TakeViews(ctx context.Context, userID, boostID string) (int64, error) {
+	tx := b.client.TxPipeline()
+	get := tx.GetDel(ctx, GetBoostViewsKey(boostID))
+	tx.ZRem(ctx, GetUserBoostsKey(userID), boostID)
+	if _, err := tx.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, err
+	}
+	views, err := get.Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return views, err
+}
+
+// GetActiveBoostsKey is a sorted set of boosted users scored by the end of their boosts
+func GetActiveBoostsKey() string {
+	return "boosts:active"
+}
+
+// GetUserBoostsKey is a sorted set of the boosts of a user that have not expired, scored by their end
+func GetUserBoostsKey(userID string) string {
+	return "boosts:user:" + userID
+}
+
+func GetBoostViewsKey(boostID string) string {
+	return "boost:views:" + boostID
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"konnect/internal/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// FeedTTL is how long a precomputed feed is served before it is rebuilt
+const FeedTTL = 24 * time.Hour
+
+type FeedCache struct {
+	client *Client
+	logger *zap.Logger
+}
+
+func NewFeeds(client *Client, logger *logger.Logger) *FeedCache {
+	return &FeedCache{
+		client: client,
+		logger: logger.With(zap.String("component", "feed_cache")),
+	}
+}
+
+// ExpireStaleFeeds sets the feed ttl on feeds that were stored without an expiry so abandoned feeds do not pile up.
+// The number of feeds that got an expiry is returned
+func (f *FeedCache) ExpireStaleFeeds(ctx context.Context, ttl time.Duration) (int, error) {
+	var expired int
+
+	// scan instead of keys to avoid blocking redis
+	iter := f.client.Scan(ctx, 0, GetUserFeedKey("*"), 500).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+
+		// -1 means the key exists without an expiry
+		remaining, err := f.client.TTL(ctx, key).Result()
+		if err != nil {
+			return expired, err
+		}
+		if remaining != -1 {
+			continue
+		}
+
+		if err := f.client.Expire(ctx, key, ttl).Err(); err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	if err := iter.Err(); err != nil {
+		return expired, err
+	}
+
+	f.logger.Info("Expired stale feeds", zap.Int("count", expired))
+	return expired, nil
+}
@@ -0,0 +1,234 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"konnect/internal/database"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrGeoIndexMissing is returned by searches before the location index has been seeded, callers should fall back to postgis
+var ErrGeoIndexMissing = errors.New("profile location index is not seeded")
+
+// NearbyCandidatesTTL bounds how long a stored candidate set outlives the lookup it was built for
+const NearbyCandidatesTTL = 30 * time.Second
+
+// GeoSeedResult reports the changes a seeding run made to the location index
+type GeoSeedResult struct {
+	// profiles written to the index
+	Profiles int
+	// members without a profile that were evicted
+	Removed int
+}
+
+type GeoCache struct {
+	client *Client
+	logger *zap.Logger
+}
+
+func NewGeo(client *Client, logger *logger.Logger) *GeoCache {
+	return &GeoCache{
+		client: client,
+		logger: logger.With(zap.String("component", "geo_cache")),
+	}
+}
+
+// SetLocation adds or moves a user in the location index
+func (g *GeoCache) SetLocation(ctx context.Context, userID string, lat, lng float64) error {
+	return g.client.GeoAdd(ctx, GetProfileLocationsKey(), &redis.GeoLocation{Name: userID, Latitude: lat, Longitude: lng}).Err()
+}
+
+// RemoveLocation drops a user from the location index
+func (g *GeoCache) RemoveLocation(ctx context.Context, userID string) error {
+	return g.client.ZRem(ctx, GetProfileLocationsKey(), userID).Err()
+}
+
+// SearchNearby returns up to count users within radius meters of the coordinates with their distance in meters, nearest first
+func (g *GeoCache) SearchNearby(ctx context.Context, lat, lng, radiusMeters float64, count int) ([]redis.GeoLocation, error) {
+	// an empty result cannot tell a missing index from an empty area
+	pipe := g.client.Pipeline()
+	exists := pipe.Exists(ctx, GetProfileLocationsKey())
+	search := pipe.GeoSearchLocation(ctx, GetProfileLocationsKey(), &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Latitude:   lat,
+			Longitude:  lng,
+			Radius:     radiusMeters,
+			RadiusUnit: "m",
+			Sort:       "ASC",
+			Count:      count,
+		},
+		WithDist: true,
+	})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	if exists.Val() == 0 {
+		return nil, ErrGeoIndexMissing
+	}
+	return search.Val(), nil
+}
+
+// StoreNearby stores up to count users within radius meters of the coordinates, nearest first, in a short-lived sorted
+// set scored by their distance and returns its key. The set restricts other lookups to nearby users, e.g. interest
+// matching, and expires on its own
+func (g *GeoCache) StoreNearby(ctx context.Context, userID string, lat, lng, radiusMeters float64, count int) (string, error) {
+	key := GetNearbyCandidatesKey(userID)
+
+	pipe := g.client.Pipeline()
+	exists := pipe.Exists(ctx, GetProfileLocationsKey())
+	pipe.GeoSearchStore(ctx, GetProfileLocationsKey(), key, &redis.GeoSearchStoreQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Latitude:   lat,
+			Longitude:  lng,
+			Radius:     radiusMeters,
+			RadiusUnit: "m",
+			Sort:       "ASC",
+			Count:      count,
+		},
+		StoreDist: true,
+	})
+	pipe.Expire(ctx, key, NearbyCandidatesTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	if exists.Val() == 0 {
+		return "", ErrGeoIndexMissing
+	}
+	return key, nil
+}
+
+// GetDistances returns the given users within radius meters of the coordinates with their distance in meters. Users
+// missing from the index are skipped
+func (g *GeoCache) GetDistances(ctx context.Context, lat, lng, radiusMeters float64, userIDs []string) ([]redis.GeoLocation, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	positions, err := g.client.GeoPos(ctx, GetProfileLocationsKey(), userIDs...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]redis.GeoLocation, 0, len(userIDs))
+	for i, position := range positions {
+		if position == nil {
+			continue
+		}
+		dist := haversine(lat, lng, position.Latitude, position.Longitude)
+		if dist > radiusMeters {
+			continue
+		}
+		locations = append(locations, redis.GeoLocation{Name: userIDs[i], Latitude: position.Latitude, Longitude: position.Longitude, Dist: dist})
+	}
+	return locations, nil
+}
+
+// SeedLocations writes every profile location to the index and evicts members whose profile no longer exists.
+// Profiles are written in place so syncs running meanwhile are not lost
+func (g *GeoCache) SeedLocations(ctx context.Context, db *database.DB) (*GeoSeedResult, error) {
+	result := &GeoSeedResult{}
+	seen := make(map[string]struct{})
+
+	var profiles []model.Profile
+	err := db.WithContext(ctx).Select("id", "user_id", "latitude", "longitude").
+		FindInBatches(&profiles, 500, func(tx *gorm.DB, batch int) error {
+			locations := make([]*redis.GeoLocation, 0, len(profiles))
+			for _, profile := range profiles {
+				userID := profile.UserID.String()
+				locations = append(locations, &redis.GeoLocation{Name: userID, Latitude: profile.Latitude, Longitude: profile.Longitude})
+				seen[userID] = struct{}{}
+			}
+			if err := g.client.GeoAdd(ctx, GetProfileLocationsKey(), locations...).Err(); err != nil {
+				return err
+			}
+			result.Profiles += len(locations)
+			return nil
+		}).Error
+	if err != nil {
+		return result, err
+	}
+
+	// members not seen may have been created after their batch was read, so only evict them once postgres confirms
+	var stale []string
+	iter := g.client.ZScan(ctx, GetProfileLocationsKey(), 0, "", 500).Iterator()
+	for iter.Next(ctx) {
+		member := iter.Val()
+		// zscan yields members followed by their scores
+		iter.Next(ctx)
+		if _, ok := seen[member]; !ok {
+			stale = append(stale, member)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return result, err
+	}
+
+	for start := 0; start < len(stale); start += 500 {
+		batch := stale[start:min(start+500, len(stale))]
+		removed, err := g.evictMissingProfiles(ctx, db, batch)
+		if err != nil {
+			return result, err
+		}
+		result.Removed += removed
+	}
+
+	g.logger.Info("Profile locations seeded", zap.Int("profiles", result.Profiles), zap.Int("removed", result.Removed))
+	return result, nil
+}
+
+// evictMissingProfiles removes the users of the batch that have no profile from the index
+func (g *GeoCache) evictMissingProfiles(ctx context.Context, db *database.DB, userIDs []string) (int, error) {
+	ids := make([]uuid.UUID, 0, len(userIDs))
+	for _, id := range userIDs {
+		// members that are not user ids can only be stale
+		if parsed, err := uuid.Parse(id); err == nil {
+			ids = append(ids, parsed)
+		}
+	}
+
+	var existing []uuid.UUID
+	if err := db.WithContext(ctx).Model(&model.Profile{}).Where("user_id IN ?", ids).Pluck("user_id", &existing).Error; err != nil {
+		return 0, err
+	}
+	keep := make(map[string]struct{}, len(existing))
+	for _, id := range existing {
+		keep[id.String()] = struct{}{}
+	}
+
+	var missing []any
+	for _, id := range userIDs {
+		if _, ok := keep[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+	if err := g.client.ZRem(ctx, GetProfileLocationsKey(), missing...).Err(); err != nil {
+		return 0, err
+	}
+	return len(missing), nil
+}
+
+// GetNearbyCandidatesKey returns a unique key for the nearby candidates of a lookup by the user
+func GetNearbyCandidatesKey(userID string) string {
+	return "candidates:nearby:" + userID + ":" + uuid.NewString()
+}
+
+// earth radius in meters redis uses for geo distances
+const earthRadius = 6372797.560856
+
+// haversine returns the distance in meters between two coordinates the way redis computes it
+func haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	lat1, lng1, lat2, lng2 = lat1*math.Pi/180, lng1*math.Pi/180, lat2*math.Pi/180, lng2*math.Pi/180
+	u := math.Sin((lat2 - lat1) / 2)
+	v := math.Sin((lng2 - lng1) / 2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(u*u+math.Cos(lat1)*math.Cos(lat2)*v*v))
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"konnect/internal/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// ImpressionRetention is how long the daily viewers of a profile are kept in redis, a week plus the current day
+	ImpressionRetention = 8 * 24 * time.Hour
+	// SeenRetention is how long a profile shown to a user is left out of their feed
+	SeenRetention = 7 * 24 * time.Hour
+)
+
+// Impression is a day of a profile whose viewers changed since the last flush
+type Impression struct {
+	UserID string
+	Day    time.Time
+}
+
+type ImpressionCache struct {
+	client *Client
+	logger *zap.Logger
+}
+
+func NewImpressions(client *Client, logger *logger.Logger) *ImpressionCache {
+	return &ImpressionCache{
+		client: client,
+		logger: logger.With(zap.String("component", "impression_cache")),
+	}
+}
+
+// RecordImpressions counts the viewer as a viewer of each profile for the day of t, marks the profiles as seen by the
+// viewer and queues the days for the flush to postgres
+func (i *ImpressionCache) RecordImpressions(ctx context.Context, viewerID string, userIDs []string, t time.Time) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	day := t.UTC().Format(time.DateOnly)
+
+	pipe := i.client.Pipeline()
+	seen := make([]redis.Z, len(userIDs))
+	pending := make([]any, len(userIDs))
+	for n, userID := range userIDs {
+		pipe.PFAdd(ctx, GetProfileViewersKey(userID, day), viewerID)
+		pipe.Expire(ctx, GetProfileViewersKey(userID, day), ImpressionRetention)
+		seen[n] = redis.Z{Score: float64(t.Unix()), Member: userID}
+		pending[n] = userID + ":" + day
+	}
+	pipe.ZAdd(ctx, GetSeenProfilesKey(viewerID), seen...)
+	pipe.ZRemRangeByScore(ctx, GetSeenProfilesKey(viewerID), "-inf", strconv.FormatInt(t.Add(-SeenRetention).Unix(), 10))
+	pipe.Expire(ctx, GetSeenProfilesKey(viewerID), SeenRetention)
+	pipe.SAdd(ctx, GetPendingImpressionsKey(), pending...)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetSeen returns the profiles shown to the viewer since t
+func (i *ImpressionCache) GetSeen(ctx context.Context, viewerID string, since time.Time) ([]string, error) {
+	return i.client.ZRangeByScore(ctx, GetSeenProfilesKey(viewerID), &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.Unix(), 10),
+		Max: "+inf",
+	}).Result()
+}
+
+// CountViewers estimates the distinct viewers of a profile over the days ending at t. Viewers on several days count once
+func (i *ImpressionCache) CountViewers(ctx context.Context, userID string, days int, t time.Time) (int64, error) {
+	keys := make([]string, days)
+	for n := range days {
+		keys[n] = GetProfileViewersKey(userID, t.UTC().AddDate(0, 0, -n).Format(time.DateOnly))
+	}
+	return i.client.PFCount(ctx, keys...).Result()
+}
+
+// PopPending takes up to count days whose viewers changed since the last flush
+func (i *ImpressionCache) PopPending(ctx context.Context, count int) ([]Impression, error) {
+	members, err := i.client.SPopN(ctx, GetPendingImpressionsKey(), int64(count)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	impressions := make([]Impression, 0, len(members))
+	for _, member := range members {
+		userID, day, ok := strings.Cut(member, ":")
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(time.DateOnly, day)
+		if err != nil {
+			continue
+		}
+		impressions = append(impressions, Impression{UserID: userID, Day: t})
+	}
+	return impressions, nil
+}
+
+// RequeuePending puts back days whose flush failed
+func (i *ImpressionCache) RequeuePending(ctx context.Context, impressions []Impression) error {
+	if len(impressions) == 0 {
+		return nil
+	}
+	members := make([]any, len(impressions))
+	for n, impression := range impressions {
+		members[n] = impression.UserID + ":" + impression.Day.Format(time.DateOnly)
+	}
+	return i.client.SAdd(ctx, GetPendingImpressionsKey(), members...).Err()
+}
+
+// CountDailyViewers estimates the distinct viewers of each impression's profile on its day
+func (i *ImpressionCache) CountDailyViewers(ctx context.Context, impressions []Impression) ([]int64, error) {
+	pipe := i.client.Pipeline()
+	counts := make([]*redis.IntCmd, len(impressions))
+	for n, impression := range impressions {
+		counts[n] = pipe.PFCount(ctx, GetProfileViewersKey(impression.UserID, impression.Day.Format(time.DateOnly)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	viewers := make([]int64, len(counts))
+	for n, count := range counts {
+		viewers[n] = count.Val()
+	}
+	return viewers, nil
+}
+
+// GetProfileViewersKey is a hyperloglog of the users a profile was shown to on a day
+func GetProfileViewersKey(userID, day string) string {
+	return "impressions:" + userID + ":" + day
+}
+
+// GetSeenProfilesKey is a sorted set of the profiles shown to a user scored by when they were last shown
+func GetSeenProfilesKey(userID string) string {
+	return "seen:" + userID
+}
+
+// GetPendingImpressionsKey is a set of userID:day members waiting for the flush to postgres
+func GetPendingImpressionsKey() string {
+	return "impressions:pending"
+}
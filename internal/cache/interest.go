@@ -4,9 +4,13 @@ import (
 	"context"
 	"konnect/internal/database"
 	"konnect/internal/logger"
+	"konnect/internal/metrics"
 	"konnect/internal/model"
+	"math"
+	"sort"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -24,6 +28,14 @@ func NewInterests(client *Client, logger *logger.Logger) *InterestCache {
 	}
 }
 
+// exec runs a pipeline or transaction of the cache and records its latency under the operation
+func (i *InterestCache) exec(ctx context.Context, operation string, pipe redis.Pipeliner) ([]redis.Cmder, error) {
+	start := time.Now()
+	cmds, err := pipe.Exec(ctx)
+	metrics.ObservePipeline("interest", operation, start, err)
+	return cmds, err
+}
+
 func (i *InterestCache) GetUserInterests(ctx context.Context, userID string) ([]string, error) {
 	return i.client.SMembers(ctx, GetUserInterestsKey(userID)).Result()
 }
@@ -37,8 +49,9 @@ func (i *InterestCache) AddUserInterests(ctx context.Context, userID string, int
 		tx.SAdd(ctx, GetUserInterestsKey(userID), interest)
 		tx.SAdd(ctx, GetInterestBucketKey(interest), userID)
 	}
+	recordTrending(ctx, tx, interests)
 
-	if _, err := tx.Exec(ctx); err != nil {
+	if _, err := i.exec(ctx, "add_interests", tx); err != nil {
 		return err
 	}
 	i.logger.Info("User interests added successfully")
@@ -54,7 +67,7 @@ func (i *InterestCache) RemoveUserInterests(ctx context.Context, userID string,
 		tx.SRem(ctx, GetInterestBucketKey(interest), userID)
 	}
 
-	if _, err := tx.Exec(ctx); err != nil {
+	if _, err := i.exec(ctx, "remove_interests", tx); err != nil {
 		return err
 	}
 	i.logger.Info("User interests removed successfully")
@@ -78,49 +91,126 @@ func (i *InterestCache) UpdateUserInterests(ctx context.Context, userID string,
 		tx.SAdd(ctx, userKey, interest)
 	}
 
-	if _, err := tx.Exec(ctx); err != nil {
+	// only newly picked interests count towards trending
+	previous := make(map[string]struct{}, len(oldInterests))
+	for _, interest := range oldInterests {
+		previous[interest] = struct{}{}
+	}
+	var added []string
+	for _, interest := range newInterests {
+		if _, ok := previous[interest]; !ok {
+			added = append(added, interest)
+		}
+	}
+	recordTrending(ctx, tx, added)
+
+	if _, err := i.exec(ctx, "update_interests", tx); err != nil {
 		return err
 	}
 	i.logger.Info("User interests updated successfully")
 	return nil
 }
 
+// MergeInterest moves every member of the source interest bucket into the target bucket and renames the interest in their interest sets
+func (i *InterestCache) MergeInterest(ctx context.Context, source, target string) error {
+	sourceKey := GetInterestBucketKey(source)
+	members, err := i.client.SMembers(ctx, sourceKey).Result()
+	if err != nil {
+		return err
+	}
+
+	// start in redis transaction
+	tx := i.client.TxPipeline()
+	for _, userID := range members {
+		userKey := GetUserInterestsKey(userID)
+		tx.SRem(ctx, userKey, source)
+		tx.SAdd(ctx, userKey, target)
+	}
+	targetKey := GetInterestBucketKey(target)
+	tx.SUnionStore(ctx, targetKey, targetKey, sourceKey)
+	tx.Del(ctx, sourceKey)
+
+	if _, err := i.exec(ctx, "merge_interest", tx); err != nil {
+		return err
+	}
+	i.logger.Info("Interest buckets merged", zap.String("source", source), zap.String("target", target), zap.Int("members", len(members)))
+	return nil
+}
+
 // GetCommonInterests retrieves the common interests among two users
 func (i *InterestCache) GetCommonInterests(ctx context.Context, userID1, userID2 string) ([]string, error) {
 	// get set intersection from user buckets
 	return i.client.SInter(ctx, GetUserInterestsKey(userID1), GetUserInterestsKey(userID2)).Result()
 }
 
-// GetUsersWithMatchingInterests retrieves all user ids with matching interests
-func (i *InterestCache) GetUsersWithMatchingInterests(ctx context.Context, userID string, interests []string, limit int) ([]string, error) {
+// MatchCandidate is a user sharing interests with the requester. Higher scores mean more and rarer shared interests
+type MatchCandidate struct {
+	UserID string
+	Score  float64
+}
+
+// GetUsersWithMatchingInterests returns up to limit users sharing the given interests, best matches first. Each shared
+// interest adds its rarity weight to a candidate's score so a niche interest in common counts more than a popular one.
+// When candidatesKey is set, only users in that set or sorted set are returned, e.g. the users near the requester stored
+// by GeoCache.StoreNearby
+func (i *InterestCache) GetUsersWithMatchingInterests(ctx context.Context, userID string, interests []string, limit int, candidatesKey string) ([]MatchCandidate, error) {
 	if len(interests) == 0 {
-		return []string{}, nil
+		return []MatchCandidate{}, nil
 	}
 
 	if limit <= 0 {
 		limit = 100
 	}
 
-	// get interest bucket keys
-	keys := make([]string, 0, len(interests))
+	// bucket sizes give the document frequency of each interest
+	pipe := i.client.Pipeline()
+	cards := make(map[string]*redis.IntCmd, len(interests))
 	for _, interest := range interests {
-		keys = append(keys, GetInterestBucketKey(interest))
+		cards[interest] = pipe.SCard(ctx, GetInterestBucketKey(interest))
 	}
-
-	// load all members while filtering out requested user
-	users, err := i.client.SUnion(ctx, keys...).Result()
-	if err != nil {
+	if _, err := i.exec(ctx, "match_weights", pipe); err != nil {
 		return nil, err
 	}
 
-	usersOut := make([]string, 0, len(users))
-	for _, user := range users {
-		if user != userID {
-			usersOut = append(usersOut, user)
+	var maxMembers int64
+	for _, cmd := range cards {
+		maxMembers = max(maxMembers, cmd.Val())
+	}
+
+	// idf-like weight relative to the most popular interest: 1 for the most popular, growing as interests get rarer
+	keys := make([]string, 0, len(cards))
+	weights := make([]float64, 0, len(cards))
+	for interest, cmd := range cards {
+		if cmd.Val() == 0 {
+			continue
 		}
+		keys = append(keys, GetInterestBucketKey(interest))
+		weights = append(weights, 1+math.Log(float64(maxMembers)/float64(cmd.Val())))
+	}
+	if len(keys) == 0 {
+		return []MatchCandidate{}, nil
+	}
+
+	// score candidates in a temporary sorted set unique to this call. Sets are read as sorted sets with a score of 1
+	dest := GetInterestMatchKey(userID)
+	tx := i.client.TxPipeline()
+	tx.ZUnionStore(ctx, dest, &redis.ZStore{Keys: keys, Weights: weights, Aggregate: "SUM"})
+	if candidatesKey != "" {
+		// the candidate scores, e.g. geohashes, must not affect the ranking
+		tx.ZInterStore(ctx, dest, &redis.ZStore{Keys: []string{dest, candidatesKey}, Weights: []float64{1, 0}, Aggregate: "SUM"})
+	}
+	tx.ZRem(ctx, dest, userID)
+	top := tx.ZRevRangeWithScores(ctx, dest, 0, int64(limit-1))
+	tx.Del(ctx, dest)
+	if _, err := i.exec(ctx, "match_candidates", tx); err != nil {
+		return nil, err
 	}
 
-	return usersOut, nil
+	candidates := make([]MatchCandidate, 0, len(top.Val()))
+	for _, z := range top.Val() {
+		candidates = append(candidates, MatchCandidate{UserID: z.Member.(string), Score: z.Score})
+	}
+	return candidates, nil
 }
 
 // GetMultipleUserInterests fetches interests for multiple users at once. their id to interests bucket mapping is returned
@@ -134,7 +224,7 @@ func (i *InterestCache) GetMultipleUserInterests(ctx context.Context, ids []stri
 	}
 
 	// execute all pipelined commands
-	if _, err := pipe.Exec(ctx); err != nil {
+	if _, err := i.exec(ctx, "get_many_interests", pipe); err != nil {
 		return nil, err
 	}
 
@@ -161,50 +251,341 @@ func (i *InterestCache) GetInterestMembersCount(ctx context.Context, interest st
 	return i.client.SCard(ctx, GetInterestBucketKey(interest)).Result()
 }
 
-// SeedActiveProfiles seeds interest buckets from profiles that were active within the last n days
-func (i *InterestCache) SeedActiveProfiles(ctx context.Context, db *database.DB, interests []string, days int) error {
+// GetInterestMembersCounts returns the number of users in each interest bucket
+func (i *InterestCache) GetInterestMembersCounts(ctx context.Context, interests []string) (map[string]int64, error) {
+	pipe := i.client.Pipeline()
+	cmds := make(map[string]*redis.IntCmd, len(interests))
+	for _, interest := range interests {
+		cmds[interest] = pipe.SCard(ctx, GetInterestBucketKey(interest))
+	}
+	if _, err := i.exec(ctx, "member_counts", pipe); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(cmds))
+	for interest, cmd := range cmds {
+		counts[interest] = cmd.Val()
+	}
+	return counts, nil
+}
+
+// TrendingRetention is how long daily interest additions are kept, bounding the trending window
+const TrendingRetention = 31 * 24 * time.Hour
+
+// interestSeedMarkKey holds the profiles.updated_at high-water mark of the last seeding run
+const interestSeedMarkKey = "seed:interests:hwm"
+
+// SeedResult reports the changes a seeding run made to the interest buckets
+type SeedResult struct {
+	// profiles compared against the cache
+	Profiles int `json:"profiles"`
+	// bucket memberships added and removed
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
+
+// SeedActiveProfiles incrementally syncs the interest buckets with profiles updated since the last run.
+// Updated profiles of inactive users and deleted profiles are evicted. Without a previous run the cache is fully reconciled
+func (i *InterestCache) SeedActiveProfiles(ctx context.Context, db *database.DB, interests []string, days int) (*SeedResult, error) {
+	mark, err := i.client.Get(ctx, interestSeedMarkKey).Time()
+	if err == redis.Nil {
+		return i.ReconcileActiveProfiles(ctx, db, interests, days)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	// get least allowed date
 	allowedTime := time.Now().AddDate(0, 0, -days)
+	result := &SeedResult{}
+	newMark := mark
+
+	// fetch changed profiles in batches of 100, deleted ones included
+	var profiles []model.Profile
+	err = db.WithContext(ctx).Unscoped().
+		Where("updated_at >= ?", mark).
+		Select("id", "user_id", "interests", "updated_at", "deleted_at").
+		FindInBatches(&profiles, 100, func(tx *gorm.DB, batch int) error {
+			userIDs := make([]uuid.UUID, 0, len(profiles))
+			for _, p := range profiles {
+				userIDs = append(userIDs, p.UserID)
+				if p.UpdatedAt.After(newMark) {
+					newMark = p.UpdatedAt
+				}
+			}
+
+			// deleted users are excluded by the default scope
+			var active []uuid.UUID
+			if err := db.WithContext(ctx).Model(&model.User{}).
+				Where("id IN ? AND last_active >= ?", userIDs, allowedTime).
+				Pluck("id", &active).Error; err != nil {
+				return err
+			}
+			activeSet := make(map[uuid.UUID]struct{}, len(active))
+			for _, id := range active {
+				activeSet[id] = struct{}{}
+			}
+
+			desired := make(map[string][]string, len(profiles))
+			for _, p := range profiles {
+				_, isActive := activeSet[p.UserID]
+				if isActive && !p.DeletedAt.Valid {
+					desired[p.UserID.String()] = p.Interests
+				} else {
+					desired[p.UserID.String()] = nil
+				}
+			}
+
+			return i.syncUsers(ctx, desired, false, result)
+		}).Error
+	if err != nil {
+		i.logger.Warn("Incremental interest seeding failed", zap.Error(err))
+		return result, err
+	}
+
+	if err := i.client.Set(ctx, interestSeedMarkKey, newMark, 0).Err(); err != nil {
+		return result, err
+	}
+
+	i.logger.Info("Seeded updated profiles into redis",
+		zap.Int("profiles", result.Profiles),
+		zap.Int("added", result.Added),
+		zap.Int("removed", result.Removed),
+		zap.Time("since", mark),
+	)
+	return result, nil
+}
+
+// ReconcileActiveProfiles diffs the interest buckets against postgres. Profiles of users active within the last n days are synced
+// and every other member of the given interest buckets is removed
+func (i *InterestCache) ReconcileActiveProfiles(ctx context.Context, db *database.DB, interests []string, days int) (*SeedResult, error) {
+	// profiles changing during the run are picked up by the next incremental run
+	startedAt := time.Now()
+
+	// get least allowed date
+	allowedTime := startedAt.AddDate(0, 0, -days)
+	result := &SeedResult{}
+
+	// expected bucket members
+	expected := make(map[string]map[string]struct{})
 
 	// fetch profiles in batches of 100
 	var profiles []model.Profile
-	err := db.Joins("JOIN users ON users.id = profiles.user_id").
+	err := db.WithContext(ctx).Joins("JOIN users ON users.id = profiles.user_id AND users.deleted_at IS NULL").
 		Where("users.last_active >= ?", allowedTime).
-		Select("profiles.user_id", "profiles.interests").
+		Select("profiles.id", "profiles.user_id", "profiles.interests").
 		FindInBatches(&profiles, 100, func(tx *gorm.DB, batch int) error {
-
-			pipe := i.client.Pipeline()
+			desired := make(map[string][]string, len(profiles))
 			for _, p := range profiles {
 				userID := p.UserID.String()
-				userInterestsKey := GetUserInterestsKey(userID)
+				desired[userID] = p.Interests
 
-				// clear old interests for the user
-				pipe.Del(ctx, userInterestsKey)
-
-				// add to interest and user bucket
 				for _, interest := range p.Interests {
-					pipe.SAdd(ctx, userInterestsKey, interest)
-					pipe.SAdd(ctx, GetInterestBucketKey(interest), userID)
+					if expected[interest] == nil {
+						expected[interest] = make(map[string]struct{})
+					}
+					expected[interest][userID] = struct{}{}
 				}
 			}
 
-			// execute pipeline for current batch
-			if _, err := pipe.Exec(ctx); err != nil {
-				i.logger.Warn("Seeding active profile failed. Skipping remaining batches...", zap.Error(err))
-				// skip remaining batches
-				return err
+			return i.syncUsers(ctx, desired, false, result)
+		}).Error
+	if err != nil {
+		i.logger.Warn("Reconciling interest buckets failed", zap.Error(err))
+		return result, err
+	}
+
+	// remove stale bucket members and their interest sets
+	for _, interest := range interests {
+		bucketKey := GetInterestBucketKey(interest)
+
+		var cursor uint64
+		for {
+			members, next, err := i.client.SScan(ctx, bucketKey, cursor, "", 100).Result()
+			if err != nil {
+				return result, err
 			}
 
-			return nil
-		}).Error
+			pipe := i.client.Pipeline()
+			for _, member := range members {
+				if _, ok := expected[interest][member]; ok {
+					continue
+				}
+				pipe.SRem(ctx, bucketKey, member)
+				pipe.SRem(ctx, GetUserInterestsKey(member), interest)
+				result.Removed++
+			}
+			if _, err := i.exec(ctx, "reconcile", pipe); err != nil {
+				return result, err
+			}
 
+			cursor = next
+			if cursor == 0 {
+				break
+			}
+		}
+	}
+
+	if err := i.client.Set(ctx, interestSeedMarkKey, startedAt, 0).Err(); err != nil {
+		return result, err
+	}
+
+	i.logger.Info("Reconciled interest buckets with postgres",
+		zap.Int("profiles", result.Profiles),
+		zap.Int("added", result.Added),
+		zap.Int("removed", result.Removed),
+		zap.Int("days", days),
+	)
+	return result, nil
+}
+
+// SyncUserInterests replaces the cached interests of a user with the given ones after a profile change. The operation is idempotent,
+// nil interests evict the user from every bucket. Added interests count towards trending
+func (i *InterestCache) SyncUserInterests(ctx context.Context, userID string, interests []string) (*SeedResult, error) {
+	result := &SeedResult{}
+	if err := i.syncUsers(ctx, map[string][]string{userID: interests}, true, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// syncUsers makes the cached interests of each user match the desired interests. Users without desired interests are evicted.
+// Seeding runs do not track trending since they replay existing interests
+func (i *InterestCache) syncUsers(ctx context.Context, desired map[string][]string, trending bool, result *SeedResult) error {
+	ids := make([]string, 0, len(desired))
+	for id := range desired {
+		ids = append(ids, id)
+	}
+	cached, err := i.GetMultipleUserInterests(ctx, ids)
 	if err != nil {
-		i.logger.Warn("Seeding active profiles failed", zap.Error(err))
 		return err
 	}
 
-	i.logger.Info("Seeded active profiles into redis", zap.Int("batch_size", 100), zap.Int("days", days))
-	return nil
+	pipe := i.client.Pipeline()
+	for userID, interests := range desired {
+		userKey := GetUserInterestsKey(userID)
+
+		want := make(map[string]struct{}, len(interests))
+		for _, interest := range interests {
+			want[interest] = struct{}{}
+		}
+		have := make(map[string]struct{}, len(cached[userID]))
+		for _, interest := range cached[userID] {
+			have[interest] = struct{}{}
+		}
+
+		var added []string
+		for interest := range want {
+			if _, ok := have[interest]; !ok {
+				pipe.SAdd(ctx, userKey, interest)
+				pipe.SAdd(ctx, GetInterestBucketKey(interest), userID)
+				added = append(added, interest)
+				result.Added++
+			}
+		}
+		if trending {
+			recordTrending(ctx, pipe, added)
+		}
+		for interest := range have {
+			if _, ok := want[interest]; !ok {
+				pipe.SRem(ctx, userKey, interest)
+				pipe.SRem(ctx, GetInterestBucketKey(interest), userID)
+				result.Removed++
+			}
+		}
+	}
+	result.Profiles += len(desired)
+
+	// execute pipeline for current batch
+	_, err = i.exec(ctx, "sync_users", pipe)
+	return err
+}
+
+// PruneInactiveProfiles removes users that have not been active within the last n days from the interest buckets and drops their interest sets.
+// The number of pruned users is returned
+func (i *InterestCache) PruneInactiveProfiles(ctx context.Context, db *database.DB, interests []string, days int) (int, error) {
+	// get least allowed date
+	allowedTime := time.Now().AddDate(0, 0, -days)
+	pruned := make(map[string]struct{})
+
+	for _, interest := range interests {
+		bucketKey := GetInterestBucketKey(interest)
+
+		// walk the bucket in chunks of 100 members
+		var cursor uint64
+		for {
+			members, next, err := i.client.SScan(ctx, bucketKey, cursor, "", 100).Result()
+			if err != nil {
+				return len(pruned), err
+			}
+
+			if len(members) > 0 {
+				var active []string
+				if err := db.WithContext(ctx).Model(&model.User{}).
+					Where("id IN ? AND last_active >= ?", members, allowedTime).
+					Pluck("id", &active).Error; err != nil {
+					i.logger.Warn("Pruning inactive profiles failed", zap.Error(err))
+					return len(pruned), err
+				}
+
+				activeSet := make(map[string]struct{}, len(active))
+				for _, id := range active {
+					activeSet[id] = struct{}{}
+				}
+
+				pipe := i.client.Pipeline()
+				for _, member := range members {
+					if _, ok := activeSet[member]; ok {
+						continue
+					}
+					pipe.SRem(ctx, bucketKey, member)
+					pipe.Del(ctx, GetUserInterestsKey(member))
+					pruned[member] = struct{}{}
+				}
+				if _, err := i.exec(ctx, "prune_inactive", pipe); err != nil {
+					i.logger.Warn("Pruning inactive profiles failed", zap.Error(err))
+					return len(pruned), err
+				}
+			}
+
+			cursor = next
+			if cursor == 0 {
+				break
+			}
+		}
+	}
+
+	i.logger.Info("Pruned inactive profiles from redis", zap.Int("pruned", len(pruned)), zap.Int("days", days))
+	return len(pruned), nil
+}
+
+// GetTrendingInterests returns how often each interest was added over the last n days, most added first
+func (i *InterestCache) GetTrendingInterests(ctx context.Context, days int) ([]redis.Z, error) {
+	keys := make([]string, 0, days)
+	now := time.Now().UTC()
+	for d := 0; d < days; d++ {
+		keys = append(keys, GetTrendingInterestsKey(now.AddDate(0, 0, -d)))
+	}
+
+	// sum the daily counters, missing days are empty sets
+	scores, err := i.client.ZUnionWithScores(ctx, redis.ZStore{Keys: keys, Aggregate: "SUM"}).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(scores, func(a, b int) bool { return scores[a].Score > scores[b].Score })
+	return scores, nil
+}
+
+// recordTrending counts interest additions in the daily trending sorted set
+func recordTrending(ctx context.Context, pipe redis.Pipeliner, interests []string) {
+	if len(interests) == 0 {
+		return
+	}
+	key := GetTrendingInterestsKey(time.Now().UTC())
+	for _, interest := range interests {
+		pipe.ZIncrBy(ctx, key, 1, interest)
+	}
+	pipe.Expire(ctx, key, TrendingRetention)
 }
 
 func GetInterestBucketKey(interest string) string {
@@ -215,6 +596,21 @@ func GetUserInterestsKey(userID string) string {
 	return "interests:user:" + userID
 }
 
+// GetTrendingInterestsKey returns the key of the interest additions of a utc day, e.g. trending:interests:2025-01-31
+func GetTrendingInterestsKey(day time.Time) string {
+	return "trending:interests:" + day.Format("2006-01-02")
+}
+
+// GetInterestMatchKey returns a unique scratch key for scoring the matches of a user
+func GetInterestMatchKey(userID string) string {
+	return "match:interests:" + userID + ":" + uuid.NewString()
+}
+
+// GetProfileLocationsKey returns the key of the geo index of profile locations by user id
+func GetProfileLocationsKey() string {
+	return "geo:profiles"
+}
+
 func GetUserFeedKey(userID string) string {
 	return "feeds:" + userID
 }
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"konnect/internal/logger"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// newTestClient connects to the redis server set with TEST_REDIS_ADDR and TEST_REDIS_PASSWORD. Tests using it are
+// skipped without one
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR is not set")
+	}
+	client := &Client{redis.NewClient(&redis.Options{Addr: addr, Password: os.Getenv("TEST_REDIS_PASSWORD")})}
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("failed to ping the test redis server: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// addTestInterests adds the users to interests unique to the test, removed with their users and trending counts after it. The unique
+// names of the interests are returned in the order given
+func addTestInterests(t *testing.T, interests *InterestCache, members map[string][]string, names ...string) []string {
+	t.Helper()
+	ctx := context.Background()
+
+	suffix := ":" + uuid.NewString()
+	unique := make(map[string]string, len(names))
+	for _, name := range names {
+		unique[name] = name + suffix
+	}
+
+	var keys []string
+	for userID, userInterests := range members {
+		mapped := make([]string, len(userInterests))
+		for i, interest := range userInterests {
+			mapped[i] = unique[interest]
+		}
+		if err := interests.AddUserInterests(ctx, userID, mapped); err != nil {
+			t.Fatalf("failed to add interests: %v", err)
+		}
+		keys = append(keys, GetUserInterestsKey(userID))
+	}
+	trending := make([]any, 0, len(unique))
+	for _, name := range unique {
+		keys = append(keys, GetInterestBucketKey(name))
+		trending = append(trending, name)
+	}
+	t.Cleanup(func() {
+		ctx := context.Background()
+		interests.client.Del(ctx, keys...)
+		interests.client.ZRem(ctx, GetTrendingInterestsKey(time.Now().UTC()), trending...)
+	})
+
+	result := make([]string, len(names))
+	for i, name := range names {
+		result[i] = unique[name]
+	}
+	return result
+}
+
+func TestMatchingInterestsRankRareInterestsFirst(t *testing.T) {
+	interests := NewInterests(newTestClient(t), &logger.Logger{Logger: zap.NewNop()})
+
+	requester, niche, popular, both := uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()
+	members := map[string][]string{
+		requester: {"hiking", "chess"},
+		niche:     {"chess"},
+		popular:   {"hiking"},
+		both:      {"hiking", "chess"},
+	}
+	// hiking is more popular than chess
+	for range 3 {
+		members[uuid.NewString()] = []string{"hiking"}
+	}
+	names := addTestInterests(t, interests, members, "hiking", "chess")
+
+	matches, err := interests.GetUsersWithMatchingInterests(context.Background(), requester, names, 3, "")
+	if err != nil {
+		t.Fatalf("failed to get matches: %v", err)
+	}
+	if len(matches) != 3 {
+		t.Fatalf("expected the limit of 3 matches, got %d", len(matches))
+	}
+
+	// both shared interests first, then the rare one ahead of the popular one
+	if matches[0].UserID != both {
+		t.Errorf("expected the user sharing both interests first, got %s", matches[0].UserID)
+	}
+	if matches[1].UserID != niche {
+		t.Errorf("expected the user sharing the rare interest second, got %s", matches[1].UserID)
+	}
+	if matches[1].Score <= matches[2].Score {
+		t.Errorf("expected the rare interest to weigh more than the popular one, got %v and %v", matches[1].Score, matches[2].Score)
+	}
+	for _, match := range matches {
+		if match.UserID == requester {
+			t.Error("expected the requester to be left out")
+		}
+	}
+}
+
+func TestMatchingInterestsWithinNearbyCandidates(t *testing.T) {
+	client := newTestClient(t)
+	interests := NewInterests(client, &logger.Logger{Logger: zap.NewNop()})
+	ctx := context.Background()
+
+	requester, near, far := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	names := addTestInterests(t, interests, map[string][]string{
+		requester: {"chess"},
+		near:      {"chess"},
+		far:       {"chess"},
+	}, "chess")
+
+	// accra and kumasi are about 200km apart
+	geo := NewGeo(client, &logger.Logger{Logger: zap.NewNop()})
+	locations := map[string][2]float64{requester: {5.6037, -0.1870}, near: {5.6100, -0.1800}, far: {6.6885, -1.6244}}
+	for userID, location := range locations {
+		if err := geo.SetLocation(ctx, userID, location[0], location[1]); err != nil {
+			t.Fatalf("failed to set location: %v", err)
+		}
+		t.Cleanup(func() { geo.RemoveLocation(context.Background(), userID) })
+	}
+
+	candidatesKey, err := geo.StoreNearby(ctx, requester, 5.6037, -0.1870, 5000, 100)
+	if err != nil {
+		t.Fatalf("failed to store nearby candidates: %v", err)
+	}
+	t.Cleanup(func() { client.Del(context.Background(), candidatesKey) })
+
+	matches, err := interests.GetUsersWithMatchingInterests(ctx, requester, names, 10, candidatesKey)
+	if err != nil {
+		t.Fatalf("failed to get matches: %v", err)
+	}
+	if len(matches) != 1 || matches[0].UserID != near {
+		t.Fatalf("expected only the nearby candidate, got %+v", matches)
+	}
+	// distances must not change the ranking
+	if matches[0].Score != 1 {
+		t.Errorf("expected the score of the shared interest alone, got %v", matches[0].Score)
+	}
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"konnect/internal/logger"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrOTPCooldown         = errors.New("verification code was sent recently")
+	ErrOTPExpired          = errors.New("verification code has expired")
+	ErrOTPInvalid          = errors.New("invalid verification code")
+	ErrOTPAttemptsExceeded = errors.New("too many invalid verification attempts")
+)
+
+// verifyPhoneOTPScript counts an attempt on the active code and returns the attempts, the hashed code and the phone
+// number. Missing codes return nil, so a code that expired is never recreated without a ttl by the attempt counter
+var verifyPhoneOTPScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return false
+end
+local attempts = redis.call("HINCRBY", KEYS[1], "attempts", 1)
+local otp = redis.call("HMGET", KEYS[1], "code", "phone")
+return {attempts, otp[1], otp[2]}
+`)
+
+type OTPCache struct {
+	client *Client
+	logger *zap.Logger
+}
+
+func NewOTP(client *Client, logger *logger.Logger) *OTPCache {
+	return &OTPCache{
+		client: client,
+		logger: logger.With(zap.String("component", "otp_cache")),
+	}
+}
+
+// CreatePhoneOTP stores a hashed verification code for the phone number of a user. A new code cannot be issued before the cooldown elapses
+func (o *OTPCache) CreatePhoneOTP(ctx context.Context, userID, phone, code string, ttl, cooldown time.Duration) error {
+	// the cooldown key only exists while resends are blocked
+	ok, err := o.client.SetNX(ctx, GetPhoneOTPCooldownKey(userID), 1, cooldown).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrOTPCooldown
+	}
+
+	key := GetPhoneOTPKey(userID)
+	tx := o.client.TxPipeline()
+	tx.Del(ctx, key)
+	tx.HSet(ctx, key, "code", hashOTP(code), "phone", phone, "attempts", 0)
+	tx.Expire(ctx, key, ttl)
+
+	if _, err := tx.Exec(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeletePhoneOTP discards the active code of the user and lifts the resend cooldown
+func (o *OTPCache) DeletePhoneOTP(ctx context.Context, userID string) error {
+	return o.client.Del(ctx, GetPhoneOTPKey(userID), GetPhoneOTPCooldownKey(userID)).Err()
+}
+
+// PhoneOTPCooldown returns how long new codes are blocked for the user, zero when a code can be issued
+func (o *OTPCache) PhoneOTPCooldown(ctx context.Context, userID string) (time.Duration, error) {
+	ttl, err := o.client.PTTL(ctx, GetPhoneOTPCooldownKey(userID)).Result()
+	if err != nil {
+		return 0, err
+	}
+	// negative ttls mean the key is missing or never expires
+	return max(ttl, 0), nil
+}
+
+// VerifyPhoneOTP checks the code against the active one of the user and returns the phone number it was issued for.
+// The code is discarded once verified or when the attempt limit is reached
+func (o *OTPCache) VerifyPhoneOTP(ctx context.Context, userID, code string, maxAttempts int) (string, error) {
+	key := GetPhoneOTPKey(userID)
+
+	res, err := verifyPhoneOTPScript.Run(ctx, o.client, []string{key}).Slice()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrOTPExpired
+	}
+	if err != nil {
+		return "", err
+	}
+	attempts, _ := res[0].(int64)
+	hashed, _ := res[1].(string)
+	phone, _ := res[2].(string)
+
+	if attempts > int64(maxAttempts) {
+		o.client.Del(ctx, key)
+		return "", ErrOTPAttemptsExceeded
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashed), []byte(hashOTP(code))) != 1 {
+		return "", ErrOTPInvalid
+	}
+
+	if err := o.client.Del(ctx, key).Err(); err != nil {
+		o.logger.Warn("failed to discard verified otp", zap.String("user_id", userID), zap.Error(err))
+	}
+	return phone, nil
+}
+
+// hashOTP avoids keeping plain verification codes in redis
+func hashOTP(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func GetPhoneOTPKey(userID string) string {
+	return "otp:phone:" + userID
+}
+
+func GetPhoneOTPCooldownKey(userID string) string {
+	return "otp:phone:cooldown:" + userID
+}
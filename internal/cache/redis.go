@@ -6,6 +6,7 @@ import (
 	"konnect/internal/config"
 	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -27,6 +28,11 @@ func New(cfg *config.Config) (*Client, error) {
 		return nil, fmt.Errorf("failed to ping redis server: %w", err)
 	}
 
+	// commands run within a request or task show up in its trace
+	if err := redisotel.InstrumentTracing(rdb); err != nil {
+		return nil, fmt.Errorf("failed to instrument redis client: %w", err)
+	}
+
 	return &Client{rdb}, nil
 }
 
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// cacheSyncStatsKey is a hash of counters shared by all api and worker replicas
+const cacheSyncStatsKey = "stats:cache_sync"
+
+type SyncStatsCache struct {
+	client *Client
+	logger *zap.Logger
+}
+
+func NewSyncStats(client *Client, logger *logger.Logger) *SyncStatsCache {
+	return &SyncStatsCache{
+		client: client,
+		logger: logger.With(zap.String("component", "sync_stats_cache")),
+	}
+}
+
+// RecordSync records a successful sync and the time it took from the database write to the cache
+func (s *SyncStatsCache) RecordSync(ctx context.Context, lag time.Duration) {
+	tx := s.client.TxPipeline()
+	tx.HIncrBy(ctx, cacheSyncStatsKey, "synced", 1)
+	tx.HSet(ctx, cacheSyncStatsKey, "last_lag_ms", lag.Milliseconds(), "last_synced_at", time.Now().Unix())
+	if _, err := tx.Exec(ctx); err != nil {
+		s.logger.Warn("failed to record cache sync", zap.Error(err))
+	}
+}
+
+// RecordFailure records a failed sync attempt. Failed attempts are retried by the worker
+func (s *SyncStatsCache) RecordFailure(ctx context.Context, cause error) {
+	tx := s.client.TxPipeline()
+	tx.HIncrBy(ctx, cacheSyncStatsKey, "failed", 1)
+	tx.HSet(ctx, cacheSyncStatsKey, "last_error", cause.Error(), "last_failed_at", time.Now().Unix())
+	if _, err := tx.Exec(ctx); err != nil {
+		s.logger.Warn("failed to record cache sync failure", zap.Error(err))
+	}
+}
+
+// RecordEnqueueFailure records a sync that could not be enqueued. The next incremental seeding run repairs it
+func (s *SyncStatsCache) RecordEnqueueFailure(ctx context.Context) {
+	if err := s.client.HIncrBy(ctx, cacheSyncStatsKey, "enqueue_failed", 1).Err(); err != nil {
+		s.logger.Warn("failed to record cache sync enqueue failure", zap.Error(err))
+	}
+}
+
+// GetStats returns the cache sync counters
+func (s *SyncStatsCache) GetStats(ctx context.Context) (*model.CacheSyncStats, error) {
+	values, err := s.client.HGetAll(ctx, cacheSyncStatsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	parseInt := func(field string) int64 {
+		n, _ := strconv.ParseInt(values[field], 10, 64)
+		return n
+	}
+	parseTime := func(field string) *time.Time {
+		n := parseInt(field)
+		if n == 0 {
+			return nil
+		}
+		t := time.Unix(n, 0)
+		return &t
+	}
+
+	return &model.CacheSyncStats{
+		Synced:        parseInt("synced"),
+		Failed:        parseInt("failed"),
+		EnqueueFailed: parseInt("enqueue_failed"),
+		LastLagMs:     parseInt("last_lag_ms"),
+		LastSyncedAt:  parseTime("last_synced_at"),
+		LastFailedAt:  parseTime("last_failed_at"),
+		LastError:     values["last_error"],
+	}, nil
+}
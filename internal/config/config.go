@@ -17,6 +17,10 @@ type Config struct {
 	DbPort             string
 	DbHost             string
 	Port               int
+	MetricsPort        int
+	WorkerPort         int
+	TraceExporter      string
+	TraceSampleRatio   float64
 	JWTSecret          string
 	JWTExpiryMinutes   time.Duration
 	MaxNearbyRadius    float64
@@ -24,10 +28,45 @@ type Config struct {
 	GoogleClientSecret string
 	GoogleCallbackURL  string
 	CloudinaryURL      string
+	AppBaseURL         string
 	RedisAddr          string
 	RedisPassword      string
 	RedisURL           string
 	CourierAPIKey      string
+	EmailTransport     string
+	EmailFrom          string
+	EmailOutboxDir     string
+	SMTPHost           string
+	SMTPPort           int
+	SMTPUsername       string
+	SMTPPassword       string
+	PushProvider       string
+	FCMCredentialsFile string
+	FCMProjectID       string
+	SMSProvider        string
+	SMSSenderID        string
+	AfricasTalkingUser string
+	AfricasTalkingKey  string
+	DefaultCountryCode string
+	OTPExpiry          time.Duration
+	OTPResendCooldown  time.Duration
+	OTPMaxAttempts     int
+	PaystackSecret     string
+	PaystackBaseURL    string
+	PaystackReturnURL  string
+	PaystackOrigins    []string
+	TrustedProxies     []string
+	FreeDailyLikes     int
+	BoostMultiplier    int
+	CacheSeedCron      string
+	CacheReconcileCron string
+	GeoSeedCron        string
+	BoostExpiryCron    string
+	ImpressionCron     string
+	InterestPruneCron  string
+	FeedExpiryCron     string
+	DigestDailyCron    string
+	DigestWeeklyCron   string
 }
 
 // New returns a config object from the env and a non-nil error if the env value is not present
@@ -47,6 +86,16 @@ func New() (*Config, error) {
 
 	// server configs
 	port := getEnvInt("PORT", 8000)
+	// the api serves its metrics on this port, apart from the public api
+	metricsPort := getEnvInt("METRICS_PORT", 9090)
+	// the worker serves its metrics and health probes on this port
+	workerPort := getEnvInt("WORKER_PORT", 9091)
+
+	// tracing
+	// trace exporter is one of otlp, stdout or none. otlp is set up with the OTEL_EXPORTER_OTLP_* variables
+	traceExporter := getEnv("TRACE_EXPORTER", "none")
+	// share of new traces that are recorded
+	traceSampleRatio := getEnvFloat("TRACE_SAMPLE_RATIO", 1)
 	jwtSecret := getEnv("JWT_SECRET", "")
 	jwtExpiry := getEnvInt("JWT_EXPIRY_MINUTES", 60)
 	maxRadius := getEnvFloat("MAX_RADIUS_METERS", 5000)
@@ -58,8 +107,64 @@ func New() (*Config, error) {
 
 	cloudinaryURL := getEnv("CLOUDINARY_URL", "")
 
+	// public url of the api used in links sent to users
+	appBaseURL := getEnv("APP_BASE_URL", "http://localhost:8000")
+
 	// notifs
-	courierAPIKey := getEnv("COURIER_API_KEY", "")
+	// email transport is one of courier, smtp or outbox
+	emailTransport := getEnv("EMAIL_TRANSPORT", "courier")
+	emailFrom := getEnv("EMAIL_FROM", "Konnect <no-reply@konnect.app>")
+	courierAPIKey := getEnvOptional("COURIER_API_KEY")
+	smtpHost := getEnv("SMTP_HOST", "localhost")
+	smtpPort := getEnvInt("SMTP_PORT", 1025)
+	smtpUsername := getEnvOptional("SMTP_USERNAME")
+	smtpPassword := getEnvOptional("SMTP_PASSWORD")
+	// outbox emails are only kept in memory when no directory is set
+	emailOutboxDir := getEnvOptional("EMAIL_OUTBOX_DIR")
+	// push provider is one of fcm or fake
+	pushProvider := getEnv("PUSH_PROVIDER", "fake")
+	fcmCredentialsFile := getEnvOptional("FCM_CREDENTIALS_FILE")
+	fcmProjectID := getEnvOptional("FCM_PROJECT_ID")
+	// sms provider is one of africastalking or log
+	smsProvider := getEnv("SMS_PROVIDER", "log")
+	smsSenderID := getEnvOptional("SMS_SENDER_ID")
+	africasTalkingUser := getEnvOptional("AFRICASTALKING_USERNAME")
+	africasTalkingKey := getEnvOptional("AFRICASTALKING_API_KEY")
+	// dialing code assumed for phone numbers without one
+	defaultCountryCode := getEnv("DEFAULT_COUNTRY_CODE", "233")
+
+	// phone verification
+	otpExpiry := getEnvInt("OTP_EXPIRY_SECONDS", 300)
+	otpResendCooldown := getEnvInt("OTP_RESEND_COOLDOWN_SECONDS", 60)
+	otpMaxAttempts := getEnvInt("OTP_MAX_ATTEMPTS", 5)
+
+	// billing
+	paystackSecret := getEnvOptional("PAYSTACK_SECRET_KEY")
+	// points the paystack client at a stand-in server, e.g. paystacktest
+	paystackBaseURL := getEnv("PAYSTACK_BASE_URL", "https://api.paystack.co/")
+	// page paystack redirects to after checkout, the dashboard setting is used when empty
+	paystackReturnURL := getEnvOptional("PAYSTACK_CALLBACK_URL")
+	// proxies allowed to set the client ip with X-Forwarded-For, no proxy is trusted when empty
+	var trustedProxies []string
+	if proxies := getEnvOptional("TRUSTED_PROXIES"); proxies != "" {
+		trustedProxies = strings.Split(proxies, ",")
+	}
+	// ips paystack sends webhooks from
+	paystackOrigins := getEnvArr("PAYSTACK_ORIGINS", []string{"52.31.139.75", "52.49.173.169", "52.214.14.220"})
+	freeDailyLikes := getEnvInt("FREE_DAILY_LIKES", 25)
+	// distances to boosted users are divided by the multiplier when ranking nearby profiles
+	boostMultiplier := getEnvInt("BOOST_MULTIPLIER", 3)
+
+	// periodic jobs, cron specs in utc
+	cacheSeedCron := getEnv("CACHE_SEED_CRON", "*/15 * * * *")
+	cacheReconcileCron := getEnv("CACHE_RECONCILE_CRON", "0 4 * * 0")
+	geoSeedCron := getEnv("GEO_SEED_CRON", "45 4 * * *")
+	boostExpiryCron := getEnv("BOOST_EXPIRY_CRON", "* * * * *")
+	impressionCron := getEnv("IMPRESSION_FLUSH_CRON", "*/10 * * * *")
+	interestPruneCron := getEnv("INTEREST_PRUNE_CRON", "30 3 * * *")
+	feedExpiryCron := getEnv("FEED_EXPIRY_CRON", "15 * * * *")
+	digestDailyCron := getEnv("DIGEST_DAILY_CRON", "0 8 * * *")
+	digestWeeklyCron := getEnv("DIGEST_WEEKLY_CRON", "0 8 * * 1")
 
 	return &Config{
 		DbName:             dbName,
@@ -68,6 +173,10 @@ func New() (*Config, error) {
 		DbPort:             dbPort,
 		DbHost:             dbHost,
 		Port:               port,
+		MetricsPort:        metricsPort,
+		WorkerPort:         workerPort,
+		TraceExporter:      traceExporter,
+		TraceSampleRatio:   traceSampleRatio,
 		JWTSecret:          jwtSecret,
 		JWTExpiryMinutes:   time.Duration(jwtExpiry) * time.Minute,
 		MaxNearbyRadius:    maxRadius,
@@ -75,10 +184,45 @@ func New() (*Config, error) {
 		GoogleClientSecret: googleClientSecret,
 		GoogleCallbackURL:  googleCallbackURL,
 		CloudinaryURL:      cloudinaryURL,
+		AppBaseURL:         appBaseURL,
 		RedisAddr:          fmt.Sprintf("%s:%d", redisHost, redisPort),
 		RedisPassword:      redisPassword,
 		RedisURL:           fmt.Sprintf("redis://:%s@%s:%d", redisPassword, redisHost, redisPort),
 		CourierAPIKey:      courierAPIKey,
+		EmailTransport:     emailTransport,
+		EmailFrom:          emailFrom,
+		EmailOutboxDir:     emailOutboxDir,
+		SMTPHost:           smtpHost,
+		SMTPPort:           smtpPort,
+		SMTPUsername:       smtpUsername,
+		SMTPPassword:       smtpPassword,
+		PushProvider:       pushProvider,
+		FCMCredentialsFile: fcmCredentialsFile,
+		FCMProjectID:       fcmProjectID,
+		SMSProvider:        smsProvider,
+		SMSSenderID:        smsSenderID,
+		AfricasTalkingUser: africasTalkingUser,
+		AfricasTalkingKey:  africasTalkingKey,
+		DefaultCountryCode: defaultCountryCode,
+		OTPExpiry:          time.Duration(otpExpiry) * time.Second,
+		OTPResendCooldown:  time.Duration(otpResendCooldown) * time.Second,
+		OTPMaxAttempts:     otpMaxAttempts,
+		PaystackSecret:     paystackSecret,
+		PaystackBaseURL:    paystackBaseURL,
+		PaystackReturnURL:  paystackReturnURL,
+		PaystackOrigins:    paystackOrigins,
+		TrustedProxies:     trustedProxies,
+		FreeDailyLikes:     freeDailyLikes,
+		BoostMultiplier:    boostMultiplier,
+		CacheSeedCron:      cacheSeedCron,
+		CacheReconcileCron: cacheReconcileCron,
+		GeoSeedCron:        geoSeedCron,
+		BoostExpiryCron:    boostExpiryCron,
+		ImpressionCron:     impressionCron,
+		InterestPruneCron:  interestPruneCron,
+		FeedExpiryCron:     feedExpiryCron,
+		DigestDailyCron:    digestDailyCron,
+		DigestWeeklyCron:   digestWeeklyCron,
 	}, nil
 }
 
@@ -93,6 +237,11 @@ func getEnv(key string, fallback string) string {
 	return val
 }
 
+// getEnvOptional returns the env value or an empty string for settings that are only required by some providers
+func getEnvOptional(key string) string {
+	return os.Getenv(key)
+}
+
 func getEnvInt(key string, fallback int) int {
 	val := os.Getenv(key)
 	if val == "" {
@@ -5,6 +5,7 @@ import (
 	"konnect/internal/config"
 	"konnect/internal/logger"
 	"konnect/internal/model"
+	"konnect/internal/tracing"
 
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
@@ -23,6 +24,11 @@ func New(cfg *config.Config, logger *logger.Logger) (*DB, error) {
 	}
 	logger.Info("database connected successfully")
 
+	// trace statements run within a request or task
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, err
+	}
+
 	// enable postgis extension
 	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS postgis;").Error; err != nil {
 		logger.Error("failed to enable postgis extension", zap.Error(err))
@@ -36,10 +42,30 @@ func New(cfg *config.Config, logger *logger.Logger) (*DB, error) {
 		&model.Swipe{},
 		&model.Match{},
 		&model.Message{},
+		&model.Notification{},
+		&model.DeviceToken{},
+		&model.NotificationPreference{},
+		&model.Interest{},
+		&model.Plan{},
+		&model.Subscription{},
+		&model.Payment{},
+		&model.WebhookEvent{},
+		&model.Boost{},
+		&model.ProfileImpression{},
 	); err != nil {
 		logger.Error("failed to run migrations", zap.Error(err))
 		return nil, err
 	}
 
+	// seed reference data
+	if err := seedInterests(db); err != nil {
+		logger.Error("failed to seed interests", zap.Error(err))
+		return nil, err
+	}
+	if err := seedPlans(db); err != nil {
+		logger.Error("failed to seed plans", zap.Error(err))
+		return nil, err
+	}
+
 	return &DB{db}, nil
 }
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"konnect/internal/model"
+	"konnect/internal/util"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// seedInterests fills an empty interest catalog with the default interests. A catalog edited by admins is left untouched
+func seedInterests(db *gorm.DB) error {
+	var count int64
+	if err := db.Unscoped().Model(&model.Interest{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var interests []model.Interest
+	for _, category := range model.InterestCategories {
+		for _, name := range model.DefaultInterests[category] {
+			interests = append(interests, model.Interest{
+				Slug:     util.Slugify(name),
+				Name:     name,
+				Category: category,
+				Active:   true,
+			})
+		}
+	}
+
+	// replicas starting together may race on the seed
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&interests).Error
+}
+
+// defaultPlans are the premium plans and boosts available out of the box. Amounts are in pesewas
+var defaultPlans = []model.Plan{
+	{Code: "premium_monthly", Name: "Premium Monthly", Kind: model.SubscriptionPlan, Amount: 5000, Currency: "GHS", DurationDays: 30, Active: true},
+	{Code: "premium_yearly", Name: "Premium Yearly", Kind: model.SubscriptionPlan, Amount: 50000, Currency: "GHS", DurationDays: 365, Active: true},
+	{Code: "boost_30m", Name: "Boost (30 minutes)", Kind: model.BoostPlan, Amount: 1000, Currency: "GHS", BoostMinutes: 30, Active: true},
+}
+
+// seedPlans creates the default plans that do not exist yet. Existing plans keep their price
+func seedPlans(db *gorm.DB) error {
+	plans := make([]model.Plan, len(defaultPlans))
+	copy(plans, defaultPlans)
+	return db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).Create(&plans).Error
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"konnect/internal/cache"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AdminHandler struct {
+	syncStats *cache.SyncStatsCache
+	logger    *zap.Logger
+}
+
+func NewAdminHandler(syncStats *cache.SyncStatsCache, logger *logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		syncStats: syncStats,
+		logger:    logger.With(zap.String("component", "admin_handler")),
+	}
+}
+
+// GetCacheSyncStats godoc
+// @Summary Get cache sync stats
+// @Description Get the counters and last lag of the profile to interest cache synchronization. Admin only
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.SuccessResponse{data=model.CacheSyncStats} "Cache sync stats retrieved successfully"
+// @Failure 401,403,500 {object} model.ErrorResponse
+// @Router /admin/cache/sync-stats [get]
+func (h *AdminHandler) GetCacheSyncStats(c *gin.Context) {
+	if _, ok := GetCurrentAdmin(c); !ok {
+		c.JSON(http.StatusForbidden, model.ErrorResponse{Message: "Forbidden"})
+		return
+	}
+
+	stats, err := h.syncStats.GetStats(c.Request.Context())
+	if err != nil {
+		h.logger.Error("failed to get cache sync stats", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get cache sync stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Cache sync stats retrieved successfully", Data: stats})
+}
@@ -57,21 +57,21 @@ func (h *AuthHandler) GoogleCallback(c *gin.Context) {
 	}
 
 	// upsert user
-	dbUser, err := h.authService.UpsertUserFromProvider(gothUser)
+	dbUser, err := h.authService.UpsertUserFromProvider(c.Request.Context(), gothUser)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to login user"})
 		return
 	}
 
 	// get user with profile
-	user, err := h.authService.GetUserByID(dbUser.ID)
+	user, err := h.authService.GetUserByID(c.Request.Context(), dbUser.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to login user"})
 		return
 	}
 
 	// generate tokens
-	token, err := h.authService.GenerateAccessToken(user)
+	token, err := h.authService.GenerateAccessToken(c.Request.Context(), user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to generate access token"})
 		return
@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"errors"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/service"
+	"konnect/internal/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type BillingHandler struct {
+	billingService *service.BillingService
+	boostService   *service.BoostService
+	logger         *zap.Logger
+}
+
+func NewBillingHandler(billingService *service.BillingService, boostService *service.BoostService, logger *logger.Logger) *BillingHandler {
+	return &BillingHandler{
+		billingService: billingService,
+		boostService:   boostService,
+		logger:         logger.With(zap.String("component", "billing_handler")),
+	}
+}
+
+// GetPlans godoc
+// @Summary Get plans
+// @Description Get the premium plans and boosts available for purchase. Amounts are in the minor unit of the currency
+// @Tags billing
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.SuccessResponse{data=[]model.Plan} "Plans retrieved successfully"
+// @Failure 401,500 {object} model.ErrorResponse
+// @Router /billing/plans [get]
+func (h *BillingHandler) GetPlans(c *gin.Context) {
+	plans, err := h.billingService.GetPlans(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get plans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Plans retrieved successfully", Data: plans})
+}
+
+// Checkout godoc
+// @Summary Checkout a plan
+// @Description Start the payment of a plan. The user completes it on the returned paystack checkout page, then the payment is verified with its reference
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CheckoutRequest true "Plan to buy"
+// @Success 201 {object} model.SuccessResponse{data=model.CheckoutResponse} "Checkout started successfully"
+// @Failure 400,401,404,500,502 {object} model.ErrorResponse
+// @Router /billing/checkout [post]
+func (h *BillingHandler) Checkout(c *gin.Context) {
+	var req model.CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid checkout data", Detail: err.Error()})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	checkout, err := h.billingService.Checkout(c.Request.Context(), user.ID, req.PlanCode)
+	if err != nil {
+		if err == service.ErrPlanNotFound {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "Plan not found"})
+			return
+		}
+		c.JSON(http.StatusBadGateway, model.ErrorResponse{Message: "Failed to start checkout"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.SuccessResponse{Message: "Checkout started successfully", Data: checkout})
+}
+
+// ChargeMobileMoney godoc
+// @Summary Pay with mobile money
+// @Description Charge a plan to the verified phone number of the current user. Depending on the status the user submits the otp sent to them (send_otp) or approves the charge on their phone (pay_offline). The payment is completed in the background
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.MobileMoneyChargeRequest true "Plan to buy"
+// @Success 201 {object} model.SuccessResponse{data=model.MobileMoneyChargeResponse} "Mobile money charge started successfully"
+// @Failure 400,401,404,500,502 {object} model.ErrorResponse
+// @Router /billing/mobile-money [post]
+func (h *BillingHandler) ChargeMobileMoney(c *gin.Context) {
+	var req model.MobileMoneyChargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid mobile money data", Detail: err.Error()})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	charge, err := h.billingService.ChargeMobileMoney(c.Request.Context(), user.ID, req.PlanCode)
+	if err != nil {
+		switch err {
+		case service.ErrPlanNotFound:
+			c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "Plan not found"})
+		case service.ErrPhoneNotVerified:
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Verify your phone number to pay with mobile money"})
+		case service.ErrUnknownMobileNetwork, util.ErrInvalidPhoneNumber:
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Mobile money is not available for your phone number"})
+		default:
+			if errors.Is(err, service.ErrPaystackClient) {
+				c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Mobile money charge was declined", Detail: err.Error()})
+				return
+			}
+			c.JSON(http.StatusBadGateway, model.ErrorResponse{Message: "Failed to start mobile money charge"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.SuccessResponse{Message: "Mobile money charge started successfully", Data: charge})
+}
+
+// SubmitMobileMoneyOTP godoc
+// @Summary Submit mobile money otp
+// @Description Submit the otp sent to the user for a mobile money charge in the send_otp status
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param reference path string true "Payment reference"
+// @Param request body model.SubmitOTPRequest true "OTP sent to the user"
+// @Success 200 {object} model.SuccessResponse{data=model.MobileMoneyChargeResponse} "OTP submitted successfully"
+// @Failure 400,401,404,409,502 {object} model.ErrorResponse
+// @Router /billing/mobile-money/{reference}/otp [post]
+func (h *BillingHandler) SubmitMobileMoneyOTP(c *gin.Context) {
+	var param model.ReferenceParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid payment reference"})
+		return
+	}
+	var req model.SubmitOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid otp", Detail: err.Error()})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	charge, err := h.billingService.SubmitMobileMoneyOTP(c.Request.Context(), user.ID, param.Reference, req.OTP)
+	if err != nil {
+		switch err {
+		case service.ErrPaymentNotFound:
+			c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "Payment not found"})
+		case service.ErrPaymentNotPending:
+			c.JSON(http.StatusConflict, model.ErrorResponse{Message: "Payment is no longer pending"})
+		default:
+			// e.g. a wrong otp
+			if errors.Is(err, service.ErrPaystackClient) {
+				c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "OTP was rejected", Detail: err.Error()})
+				return
+			}
+			c.JSON(http.StatusBadGateway, model.ErrorResponse{Message: "Failed to submit otp"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "OTP submitted successfully", Data: charge})
+}
+
+// VerifyPayment godoc
+// @Summary Verify payment
+// @Description Check the status of a payment with paystack. A successful payment activates or extends the subscription
+// @Tags billing
+// @Produce json
+// @Security BearerAuth
+// @Param reference path string true "Payment reference"
+// @Success 200 {object} model.SuccessResponse{data=model.Payment} "Payment verified successfully"
+// @Failure 400,401,404,502 {object} model.ErrorResponse
+// @Router /billing/payments/{reference} [get]
+func (h *BillingHandler) VerifyPayment(c *gin.Context) {
+	var param model.ReferenceParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid payment reference"})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	payment, err := h.billingService.VerifyPayment(c.Request.Context(), user.ID, param.Reference)
+	if err != nil {
+		if err == service.ErrPaymentNotFound {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "Payment not found"})
+			return
+		}
+		c.JSON(http.StatusBadGateway, model.ErrorResponse{Message: "Failed to verify payment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Payment verified successfully", Data: payment})
+}
+
+// GetSubscription godoc
+// @Summary Get subscription
+// @Description Get the subscription of the current user and the features available to them
+// @Tags billing
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.SuccessResponse{data=model.SubscriptionResponse} "Subscription retrieved successfully"
+// @Failure 401,500 {object} model.ErrorResponse
+// @Router /billing/subscription [get]
+func (h *BillingHandler) GetSubscription(c *gin.Context) {
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	subscription, err := h.billingService.GetSubscription(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get subscription"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Subscription retrieved successfully", Data: subscription})
+}
+
+// GetBoosts godoc
+// @Summary Get boosts
+// @Description Get the latest boosts of the current user with their views and the likes received during each boost. Boosts are bought like plans, with a plan of the boost kind, and rank the user higher in nearby profiles while they run
+// @Tags billing
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.SuccessResponse{data=[]model.Boost} "Boosts retrieved successfully"
+// @Failure 401,500 {object} model.ErrorResponse
+// @Router /billing/boosts [get]
+func (h *BillingHandler) GetBoosts(c *gin.Context) {
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	boosts, err := h.boostService.GetBoosts(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get boosts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Boosts retrieved successfully", Data: boosts})
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type DeviceHandler struct {
+	deviceService *service.DeviceService
+	logger        *zap.Logger
+}
+
+func NewDeviceHandler(deviceService *service.DeviceService, logger *logger.Logger) *DeviceHandler {
+	return &DeviceHandler{
+		deviceService: deviceService,
+		logger:        logger.With(zap.String("component", "device_handler")),
+	}
+}
+
+// RegisterDevice godoc
+// @Summary Register device
+// @Description Register a push notification token for the authenticated user's device
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.RegisterDeviceRequest true "Device data"
+// @Success 201 {object} model.SuccessResponse{data=model.DeviceToken} "Device registered successfully"
+// @Failure 400,401,500 {object} model.ErrorResponse
+// @Router /devices [post]
+func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
+	var req model.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid device data", Detail: err.Error()})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	device, err := h.deviceService.RegisterDevice(c.Request.Context(), user.ID, req.Token, req.Platform)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to register device"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.SuccessResponse{Message: "Device registered successfully", Data: device})
+}
+
+// UnregisterDevice godoc
+// @Summary Unregister device
+// @Description Remove a push notification token of the authenticated user, e.g. on logout
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.UnregisterDeviceRequest true "Device token"
+// @Success 200 {object} model.SuccessResponse "Device unregistered successfully"
+// @Failure 400,401,404,500 {object} model.ErrorResponse
+// @Router /devices [delete]
+func (h *DeviceHandler) UnregisterDevice(c *gin.Context) {
+	var req model.UnregisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid device data", Detail: err.Error()})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	if err := h.deviceService.UnregisterDevice(c.Request.Context(), user.ID, req.Token); err != nil {
+		if err == service.ErrDeviceNotFound {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "Device not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to unregister device"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Device unregistered successfully"})
+}
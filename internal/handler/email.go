@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type EmailTemplateHandler struct {
+	templates *template.Registry
+	logger    *zap.Logger
+}
+
+func NewEmailTemplateHandler(templates *template.Registry, logger *logger.Logger) *EmailTemplateHandler {
+	return &EmailTemplateHandler{
+		templates: templates,
+		logger:    logger.With(zap.String("component", "email_template_handler")),
+	}
+}
+
+// GetTemplates godoc
+// @Summary List email templates
+// @Description List the registered email templates and their locales. Admin only
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.SuccessResponse{data=[]template.TemplateInfo} "Email templates retrieved successfully"
+// @Failure 401,403 {object} model.ErrorResponse
+// @Router /admin/email-templates [get]
+func (h *EmailTemplateHandler) GetTemplates(c *gin.Context) {
+	if _, ok := GetCurrentAdmin(c); !ok {
+		c.JSON(http.StatusForbidden, model.ErrorResponse{Message: "Forbidden"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Email templates retrieved successfully", Data: h.templates.Templates()})
+}
+
+// PreviewTemplate godoc
+// @Summary Preview email template
+// @Description Render an email template in a locale with sample data. Provided data overrides the samples. Admin only
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Param request body model.PreviewEmailTemplateRequest false "Preview options"
+// @Success 200 {object} model.SuccessResponse{data=template.Email} "Email template rendered successfully"
+// @Failure 400,401,403,404 {object} model.ErrorResponse
+// @Router /admin/email-templates/{id}/preview [post]
+func (h *EmailTemplateHandler) PreviewTemplate(c *gin.Context) {
+	if _, ok := GetCurrentAdmin(c); !ok {
+		c.JSON(http.StatusForbidden, model.ErrorResponse{Message: "Forbidden"})
+		return
+	}
+
+	var param model.TemplateIDParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid template ID"})
+		return
+	}
+
+	var req model.PreviewEmailTemplateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid preview data", Detail: err.Error()})
+			return
+		}
+	}
+	if req.Locale == "" {
+		req.Locale = template.DefaultLocale
+	}
+
+	email, err := h.templates.Render(param.ID, req.Locale, template.PreviewData(param.ID, req.Data))
+	if err != nil {
+		if err == template.ErrTemplateNotFound {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "Email template not found"})
+			return
+		}
+		// missing or malformed data
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Failed to render email template", Detail: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Email template rendered successfully", Data: email})
+}
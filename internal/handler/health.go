@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"konnect/internal/health"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+	logger  *zap.Logger
+}
+
+func NewHealthHandler(checker *health.Checker, logger *logger.Logger) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+		logger:  logger.With(zap.String("component", "health_handler")),
+	}
+}
+
+// Live godoc
+// @Summary Liveness probe
+// @Description Report that the api process is running. Dependencies are not checked
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.SuccessResponse{data=model.HealthResponse} "Service live"
+// @Router /health/live [get]
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Service live", Data: model.HealthResponse{Status: model.HealthOK}})
+}
+
+// Ready godoc
+// @Summary Readiness probe
+// @Description Check postgres, redis, the task broker and optional dependencies. The api is unavailable when a required dependency is down and degraded when an optional one is
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.SuccessResponse{data=model.HealthResponse} "Service ready"
+// @Failure 503 {object} model.ErrorResponse{detail=model.HealthResponse} "Service unavailable"
+// @Router /health/ready [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	resp := h.checker.Check(c.Request.Context())
+	if resp.Status == model.HealthUnavailable {
+		h.logger.Warn("service unavailable", zap.Any("components", resp.Components), logger.RequestIDField(c.Request.Context()))
+		c.JSON(http.StatusServiceUnavailable, model.ErrorResponse{Message: "Service unavailable", Detail: resp})
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Service ready", Data: resp})
+}
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type InterestHandler struct {
+	interestService *service.InterestService
+	logger          *zap.Logger
+}
+
+func NewInterestHandler(interestService *service.InterestService, logger *logger.Logger) *InterestHandler {
+	return &InterestHandler{
+		interestService: interestService,
+		logger:          logger.With(zap.String("component", "interest_handler")),
+	}
+}
+
+// GetInterests godoc
+// @Summary Get interests
+// @Description Get the interests users can pick, grouped by category. Labels are translated when the locale is supported
+// @Tags interests
+// @Produce json
+// @Param locale query string false "Label locale" default(en)
+// @Success 200 {object} model.SuccessResponse{data=[]model.InterestCategoryResponse} "Interests retrieved successfully"
+// @Failure 400,500 {object} model.ErrorResponse
+// @Router /interests [get]
+func (h *InterestHandler) GetInterests(c *gin.Context) {
+	var query model.GetInterestsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters", Detail: err.Error()})
+		return
+	}
+
+	catalog, err := h.interestService.GetCatalog(c.Request.Context(), query.Locale)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get interests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Interests retrieved successfully", Data: catalog})
+}
+
+// GetAllInterests godoc
+// @Summary List all interests
+// @Description List the whole interest catalog including retired and merged interests. Admin only
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.SuccessResponse{data=[]model.Interest} "Interests retrieved successfully"
+// @Failure 401,403,500 {object} model.ErrorResponse
+// @Router /admin/interests [get]
+func (h *InterestHandler) GetAllInterests(c *gin.Context) {
+	if _, ok := GetCurrentAdmin(c); !ok {
+		c.JSON(http.StatusForbidden, model.ErrorResponse{Message: "Forbidden"})
+		return
+	}
+
+	interests, err := h.interestService.GetAllInterests(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get interests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Interests retrieved successfully", Data: interests})
+}
+
+// CreateInterest godoc
+// @Summary Create interest
+// @Description Add an interest to the catalog. The slug is derived from the name when omitted. Admin only
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CreateInterestRequest true "Interest data"
+// @Success 201 {object} model.SuccessResponse{data=model.Interest} "Interest created successfully"
+// @Failure 400,401,403,409,500 {object} model.ErrorResponse
+// @Router /admin/interests [post]
+func (h *InterestHandler) CreateInterest(c *gin.Context) {
+	if _, ok := GetCurrentAdmin(c); !ok {
+		c.JSON(http.StatusForbidden, model.ErrorResponse{Message: "Forbidden"})
+		return
+	}
+
+	var req model.CreateInterestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid interest data", Detail: err.Error()})
+		return
+	}
+
+	interest, err := h.interestService.CreateInterest(c.Request.Context(), req)
+	if err != nil {
+		if err == service.ErrInterestExists {
+			c.JSON(http.StatusConflict, model.ErrorResponse{Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to create interest"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, model.SuccessResponse{Message: "Interest created successfully", Data: interest})
+}
+
+// UpdateInterest godoc
+// @Summary Update interest
+// @Description Change the category, locale labels or active flag of an interest. Admin only
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Interest ID"
+// @Param request body model.UpdateInterestRequest true "Interest data"
+// @Success 200 {object} model.SuccessResponse{data=model.Interest} "Interest updated successfully"
+// @Failure 400,401,403,404,500 {object} model.ErrorResponse
+// @Router /admin/interests/{id} [patch]
+func (h *InterestHandler) UpdateInterest(c *gin.Context) {
+	if _, ok := GetCurrentAdmin(c); !ok {
+		c.JSON(http.StatusForbidden, model.ErrorResponse{Message: "Forbidden"})
+		return
+	}
+
+	var param model.IDParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid interest ID"})
+		return
+	}
+
+	var req model.UpdateInterestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid interest data", Detail: err.Error()})
+		return
+	}
+
+	interest, err := h.interestService.UpdateInterest(c.Request.Context(), param.GetID(), req)
+	if err != nil {
+		if err == service.ErrInterestNotFound {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "Interest not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to update interest"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Interest updated successfully", Data: interest})
+}
+
+// RetireInterest godoc
+// @Summary Retire interest
+// @Description Hide an interest from the catalog so it cannot be picked anymore. Profiles that have it keep it. Admin only
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Interest ID"
+// @Success 200 {object} model.SuccessResponse{data=model.Interest} "Interest retired successfully"
+// @Failure 400,401,403,404,500 {object} model.ErrorResponse
+// @Router /admin/interests/{id} [delete]
+func (h *InterestHandler) RetireInterest(c *gin.Context) {
+	if _, ok := GetCurrentAdmin(c); !ok {
+		c.JSON(http.StatusForbidden, model.ErrorResponse{Message: "Forbidden"})
+		return
+	}
+
+	var param model.IDParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid interest ID"})
+		return
+	}
+
+	interest, err := h.interestService.RetireInterest(c.Request.Context(), param.GetID())
+	if err != nil {
+		if err == service.ErrInterestNotFound {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "Interest not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to retire interest"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Interest retired successfully", Data: interest})
+}
+
+// MergeInterest godoc
+// @Summary Merge interest
+// @Description Replace an interest with another one on every profile and in the interest cache, then retire it. Admin only
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID of the interest to merge"
+// @Param request body model.MergeInterestRequest true "Merge target"
+// @Success 200 {object} model.SuccessResponse{data=model.MergeInterestResponse} "Interest merged successfully"
+// @Failure 400,401,403,404,500 {object} model.ErrorResponse
+// @Router /admin/interests/{id}/merge [post]
+func (h *InterestHandler) MergeInterest(c *gin.Context) {
+	admin, ok := GetCurrentAdmin(c)
+	if !ok {
+		c.JSON(http.StatusForbidden, model.ErrorResponse{Message: "Forbidden"})
+		return
+	}
+
+	var param model.IDParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid interest ID"})
+		return
+	}
+
+	var req model.MergeInterestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid merge target", Detail: err.Error()})
+		return
+	}
+
+	result, err := h.interestService.MergeInterest(c.Request.Context(), param.GetID(), req.TargetID)
+	if err != nil {
+		switch err {
+		case service.ErrInterestNotFound:
+			c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "Interest not found"})
+		case service.ErrInterestMergeSelf, service.ErrInterestMergeTarget:
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to merge interest"})
+		}
+		return
+	}
+
+	h.logger.Info("interest merged",
+		zap.String("admin_id", admin.ID.String()),
+		zap.String("source_id", param.ID),
+		zap.String("target", result.Target.Name),
+		zap.Int64("profiles", result.ProfilesMigrated),
+	)
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Interest merged successfully", Data: result})
+}
+
+// GetInterestStats godoc
+// @Summary Get interest stats
+// @Description Get the number of members of each interest, most popular first. Only profiles within the radius are counted when coordinates are given
+// @Tags interests
+// @Produce json
+// @Security BearerAuth
+// @Param lat query number false "Latitude, required with lng"
+// @Param lng query number false "Longitude, required with lat"
+// @Param radius query number false "Radius in meters" default(5000)
+// @Param locale query string false "Label locale" default(en)
+// @Success 200 {object} model.SuccessResponse{data=[]model.InterestStat} "Interest stats retrieved successfully"
+// @Failure 400,401,500 {object} model.ErrorResponse
+// @Router /interests/stats [get]
+func (h *InterestHandler) GetInterestStats(c *gin.Context) {
+	var query model.InterestStatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters", Detail: err.Error()})
+		return
+	}
+
+	stats, err := h.interestService.GetStats(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get interest stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Interest stats retrieved successfully", Data: stats})
+}
+
+// GetTrendingInterests godoc
+// @Summary Get trending interests
+// @Description Get the interests added to profiles the most over the last days
+// @Tags interests
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Number of days" default(7)
+// @Param limit query int false "Number of interests" default(10)
+// @Param locale query string false "Label locale" default(en)
+// @Success 200 {object} model.SuccessResponse{data=[]model.TrendingInterest} "Trending interests retrieved successfully"
+// @Failure 400,401,500 {object} model.ErrorResponse
+// @Router /interests/trending [get]
+func (h *InterestHandler) GetTrendingInterests(c *gin.Context) {
+	var query model.TrendingInterestsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters", Detail: err.Error()})
+		return
+	}
+
+	trending, err := h.interestService.GetTrending(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get trending interests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Trending interests retrieved successfully", Data: trending})
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// RequestIDHeader carries the request id from clients and proxies and back in responses
+	RequestIDHeader = "X-Request-ID"
+	// longest request id accepted from clients
+	maxRequestIDLength = 128
+)
+
+// RequestID reuses the request id sent by the client or a proxy, or generates one. The id is returned in the response
+// and carried by the request context, where services and enqueued tasks pick it up
+func (m *Middleware) RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// AccessLog logs every request once it is handled. Server errors are logged as errors, client errors as warnings
+func (m *Middleware) AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// the matched route keeps ids out of the path, unmatched requests keep their path
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		fields := []zap.Field{
+			zap.String("component", "http"),
+			zap.String("method", c.Request.Method),
+			zap.String("route", route),
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("latency", time.Since(start)),
+			zap.Int("size", c.Writer.Size()),
+			zap.String("client_ip", c.ClientIP()),
+			zap.String("user_agent", c.Request.UserAgent()),
+			logger.RequestIDField(c.Request.Context()),
+		}
+		if user, ok := GetCurrentUser(c); ok {
+			fields = append(fields, zap.String("user_id", user.ID.String()))
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		switch status := c.Writer.Status(); {
+		case status >= http.StatusInternalServerError:
+			m.logger.Error("request handled", fields...)
+		case status >= http.StatusBadRequest:
+			m.logger.Warn("request handled", fields...)
+		default:
+			m.logger.Info("request handled", fields...)
+		}
+	}
+}
+
+// Recovery turns panics in handlers into 500 responses and logs them with the request id
+func (m *Middleware) Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, err any) {
+		m.logger.Error("panic recovered",
+			zap.String("component", "http"),
+			zap.String("path", c.Request.URL.Path),
+			zap.Any("panic", err),
+			zap.Stack("stack"),
+			logger.RequestIDField(c.Request.Context()),
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Internal server error"})
+	})
+}
+
+// validRequestID accepts short printable ids so clients cannot inject into logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -84,7 +84,10 @@ func (m *Middleware) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		user := model.AuthenticatedUser{ID: userID, Username: username, Role: model.UserRole(role)}
+		// tokens issued before phone verification existed do not carry the claim
+		phoneVerified, _ := claims["phoneVerified"].(bool)
+
+		user := model.AuthenticatedUser{ID: userID, Username: username, Role: model.UserRole(role), PhoneVerified: phoneVerified}
 
 		// Add user info to request context
 		c.Set(string(UserKey), user)
@@ -122,5 +125,5 @@ func GetCurrentAdmin(c *gin.Context) (model.AuthenticatedUser, bool) {
 }
 
 func (m *Middleware) logAuthWarning(c *gin.Context, msg string, err error) {
-	m.logger.Warn(msg, zap.String("component", "auth_middleware"), zap.String("path", c.Request.URL.Path), zap.Error(err))
+	m.logger.Warn(msg, zap.String("component", "auth_middleware"), zap.String("path", c.Request.URL.Path), zap.Error(err), logger.RequestIDField(c.Request.Context()))
 }
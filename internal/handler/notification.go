@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	htmltemplate "html/template"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.uber.org/zap"
+)
+
+// unsubscribePage is shown by the unsubscribe link of emails. Users confirm with a POST, so link scanners and
+// prefetchers opening the link do not unsubscribe them
+var unsubscribePage = htmltemplate.Must(htmltemplate.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Unsubscribe from Konnect emails</title>
+</head>
+<body style="max-width:480px;margin:48px auto;padding:0 16px;font-family:Helvetica,Arial,sans-serif;color:#1a1a1a;">
+{{if .Unsubscribed}}
+<p style="font-size:16px;line-height:24px;">You have been unsubscribed from Konnect notification emails. Emails about your account are still sent.</p>
+{{else}}
+<p style="font-size:16px;line-height:24px;">Stop receiving Konnect notification emails? Emails about your account are still sent.</p>
+<form method="post" action="?token={{.Token}}">
+<input type="hidden" name="List-Unsubscribe" value="One-Click">
+<button type="submit" style="padding:12px 24px;border:0;border-radius:4px;background:#e8465c;color:#fff;font-size:16px;">Unsubscribe</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+	preferenceService   *service.NotificationPreferenceService
+	logger              *zap.Logger
+}
+
+func NewNotificationHandler(notificationService *service.NotificationService, preferenceService *service.NotificationPreferenceService, logger *logger.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		preferenceService:   preferenceService,
+		logger:              logger.With(zap.String("component", "notification_handler")),
+	}
+}
+
+// GetNotifications godoc
+// @Summary Get notifications
+// @Description Get paginated in-app notifications of the authenticated user along with the unread count
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param unreadOnly query bool false "Only return unread notifications" default(false)
+// @Param limit query number false "Limit" default(20)
+// @Param offset query number false "Offset" default(0)
+// @Success 200 {object} model.SuccessResponse{data=model.NotificationsResponse} "Notifications retrieved successfully"
+// @Failure 400,401,500 {object} model.ErrorResponse
+// @Router /notifications [get]
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	var query model.GetNotificationsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Message: "Invalid query parameters",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	notifications, err := h.notificationService.GetNotifications(c.Request.Context(), user.ID, query.UnreadOnly, query.Limit, query.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get notifications"})
+		return
+	}
+
+	unreadCount, err := h.notificationService.CountUnread(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Message: "Notifications retrieved successfully",
+		Data:    model.NotificationsResponse{Notifications: notifications, UnreadCount: unreadCount},
+	})
+}
+
+// MarkAsRead godoc
+// @Summary Mark notification as read
+// @Description Mark a single notification of the authenticated user as read
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} model.SuccessResponse{data=model.Notification} "Notification marked as read"
+// @Failure 400,401,404,500 {object} model.ErrorResponse
+// @Router /notifications/{id}/read [patch]
+func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
+	var param model.IDParam
+	if err := c.ShouldBindUri(&param); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid notification ID"})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	notification, err := h.notificationService.MarkAsRead(c.Request.Context(), user.ID, param.GetID())
+	if err != nil {
+		if err == service.ErrNotificationNotFound {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to mark notification as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Notification marked as read", Data: notification})
+}
+
+// MarkAllAsRead godoc
+// @Summary Mark all notifications as read
+// @Description Mark every unread notification of the authenticated user as read
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.SuccessResponse{data=model.MarkAllNotificationsReadResponse} "Notifications marked as read"
+// @Failure 401,500 {object} model.ErrorResponse
+// @Router /notifications/read [patch]
+func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	updated, err := h.notificationService.MarkAllAsRead(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to mark notifications as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Message: "Notifications marked as read",
+		Data:    model.MarkAllNotificationsReadResponse{Updated: updated},
+	})
+}
+
+// GetPreferences godoc
+// @Summary Get notification preferences
+// @Description Get the notification settings of the authenticated user. Users that never changed their settings get the defaults
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.SuccessResponse{data=model.NotificationPreference} "Notification preferences retrieved successfully"
+// @Failure 401,500 {object} model.ErrorResponse
+// @Router /notifications/preferences [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	prefs, err := h.preferenceService.GetPreferences(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Notification preferences retrieved successfully", Data: prefs})
+}
+
+// UpdatePreferences godoc
+// @Summary Update notification preferences
+// @Description Replace the notification settings of the authenticated user: email and push per notification type (match, message, like), instant emails or a daily or weekly digest, and quiet hours (HH:MM) in an IANA timezone. Notifications during quiet hours are delivered when they end
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.UpdateNotificationPreferencesRequest true "Notification preferences"
+// @Success 200 {object} model.SuccessResponse{data=model.NotificationPreference} "Notification preferences updated successfully"
+// @Failure 400,401,500 {object} model.ErrorResponse
+// @Router /notifications/preferences [put]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req model.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid notification preferences", Detail: err.Error()})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	prefs, err := h.preferenceService.UpdatePreferences(c.Request.Context(), user.ID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownNotificationType) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid notification preferences", Detail: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to update notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Notification preferences updated successfully", Data: prefs})
+}
+
+// UnsubscribePage godoc
+// @Summary Unsubscribe confirmation page
+// @Description Page opened by the unsubscribe link in the email footer. It asks the user to confirm and does not unsubscribe them
+// @Tags notifications
+// @Produce html
+// @Param token query string true "Unsubscribe token"
+// @Success 200 {string} string "Confirmation page"
+// @Failure 400 {object} model.ErrorResponse
+// @Router /notifications/unsubscribe [get]
+func (h *NotificationHandler) UnsubscribePage(c *gin.Context) {
+	var query model.UnsubscribeQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid unsubscribe link"})
+		return
+	}
+	h.renderUnsubscribePage(c, query.Token, false)
+}
+
+// Unsubscribe godoc
+// @Summary Unsubscribe from emails
+// @Description Unsubscribe from notification emails with the signed token of the email. Used by the confirmation page and by mail clients supporting one-click unsubscribe (rfc 8058). Transactional emails are still sent
+// @Tags notifications
+// @Produce json,html
+// @Param token query string true "Unsubscribe token"
+// @Success 200 {object} model.SuccessResponse "Unsubscribed successfully"
+// @Failure 400,500 {object} model.ErrorResponse
+// @Router /notifications/unsubscribe [post]
+func (h *NotificationHandler) Unsubscribe(c *gin.Context) {
+	var query model.UnsubscribeQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid unsubscribe link"})
+		return
+	}
+
+	if err := h.preferenceService.Unsubscribe(c.Request.Context(), query.Token); err != nil {
+		if err == service.ErrInvalidUnsubscribeToken {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid unsubscribe link"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to unsubscribe"})
+		return
+	}
+
+	// browsers submitting the confirmation page get a page back
+	if c.NegotiateFormat(binding.MIMEJSON, binding.MIMEHTML) == binding.MIMEHTML {
+		h.renderUnsubscribePage(c, query.Token, true)
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Unsubscribed successfully"})
+}
+
+func (h *NotificationHandler) renderUnsubscribePage(c *gin.Context, token string, unsubscribed bool) {
+	var page bytes.Buffer
+	if err := unsubscribePage.Execute(&page, map[string]any{"Token": token, "Unsubscribed": unsubscribed}); err != nil {
+		h.logger.Error("failed to render unsubscribe page", zap.Error(err), logger.RequestIDField(c.Request.Context()))
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to load page"})
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
@@ -14,13 +14,17 @@ import (
 type ProfileHandler struct {
 	profileService    *service.ProfileService
 	cloudinaryService *service.CloudinaryService
+	interestService   *service.InterestService
+	impressionService *service.ImpressionService
 	logger            *zap.Logger
 }
 
-func NewProfileHandler(profileService *service.ProfileService, cloudinaryService *service.CloudinaryService, logger *logger.Logger) *ProfileHandler {
+func NewProfileHandler(profileService *service.ProfileService, cloudinaryService *service.CloudinaryService, interestService *service.InterestService, impressionService *service.ImpressionService, logger *logger.Logger) *ProfileHandler {
 	return &ProfileHandler{
 		profileService:    profileService,
 		cloudinaryService: cloudinaryService,
+		interestService:   interestService,
+		impressionService: impressionService,
 		logger:            logger.With(zap.String("component", "profile_handler")),
 	}
 }
@@ -47,7 +51,12 @@ func (h *ProfileHandler) CreateProfile(c *gin.Context) {
 		return
 	}
 
-	if !model.ValidateInterests(req.Interests) {
+	valid, err := h.interestService.ValidateInterests(c.Request.Context(), req.Interests)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to validate interests"})
+		return
+	}
+	if !valid {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Message: "Unknown interest provided in profile data",
 		})
@@ -83,7 +92,7 @@ func (h *ProfileHandler) CreateProfile(c *gin.Context) {
 		Longitude:          req.Longitude,
 	}
 
-	if err := h.profileService.CreateProfile(profile); err != nil {
+	if err := h.profileService.CreateProfile(c.Request.Context(), profile); err != nil {
 		if err == service.ErrProfileExists {
 			c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Profile already exists"})
 			return
@@ -114,7 +123,7 @@ func (h *ProfileHandler) GetCurrentUserProfile(c *gin.Context) {
 		return
 	}
 
-	profile, err := h.profileService.GetProfileByUserID(user.ID)
+	profile, err := h.profileService.GetProfileByUserID(c.Request.Context(), user.ID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "Profile not found"})
 		return
@@ -123,6 +132,38 @@ func (h *ProfileHandler) GetCurrentUserProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Profile retrieved successfully", Data: profile})
 }
 
+// GetProfileViews godoc
+// @Summary Get profile views
+// @Description Estimate how many distinct people were shown the profile of the current user in discovery over the last days
+// @Tags profiles
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Days to count, including today" default(7) minimum(1) maximum(7)
+// @Success 200 {object} model.SuccessResponse{data=model.ProfileViewsResponse} "Profile views retrieved successfully"
+// @Failure 400,401,500 {object} model.ErrorResponse
+// @Router /profiles/me/views [get]
+func (h *ProfileHandler) GetProfileViews(c *gin.Context) {
+	var query model.ProfileViewsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters", Detail: err.Error()})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	views, err := h.impressionService.GetViewers(c.Request.Context(), user.ID, query.Days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get profile views"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Profile views retrieved successfully", Data: views})
+}
+
 // GetNearbyProfiles godoc
 // @Summary Get nearby profiles
 // @Description Get profiles within specified radius of coordinates
@@ -133,7 +174,9 @@ func (h *ProfileHandler) GetCurrentUserProfile(c *gin.Context) {
 // @Param lng query number true "Longitude"
 // @Param radius query number false "Radius in meters" default(5000)
 // @Param limit query number false "Limit" default(20)
-// @Param offset query number false "Offset" default(0)
+// @Param offset query number false "Offset, ignored with excludeSeen" default(0)
+// @Param excludeSeen query boolean false "Leave out profiles shown during the last week. Profiles are seen once returned, so the next page is requested without an offset"
+// @Param sort query string false "Order by distance, or by shared interests among the nearest profiles. Interest ordering only returns profiles sharing an interest and falls back to distance when the location index is unavailable" Enums(distance, interests) default(distance)
 // @Success 200 {object} model.SuccessResponse{data=[]model.Profile} "Nearby profiles retrieved successfully"
 // @Failure 400,401,500 {object} model.ErrorResponse
 // @Router /profiles/nearby [get]
@@ -153,7 +196,7 @@ func (h *ProfileHandler) GetNearbyProfiles(c *gin.Context) {
 		return
 	}
 
-	profiles, err := h.profileService.GetNearbyProfiles(user.ID, query.Lat, query.Lng, query.Radius, query.Offset, query.Limit)
+	profiles, err := h.profileService.GetNearbyProfiles(c.Request.Context(), user.ID, query.Lat, query.Lng, query.Radius, query.Offset, query.Limit, query.ExcludeSeen, query.Sort)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get nearby profiles"})
 		return
@@ -179,7 +222,7 @@ func (h *ProfileHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	profile, err := h.profileService.GetProfile(param.GetID())
+	profile, err := h.profileService.GetProfile(c.Request.Context(), param.GetID())
 	if err != nil {
 		c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "Profile not found"})
 		return
@@ -226,8 +269,13 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	// get validate interests if provided
-	if req.Interests != nil {
-		if len(req.Interests) != 0 && !model.ValidateInterests(req.Interests) {
+	if len(req.Interests) != 0 {
+		valid, err := h.interestService.ValidateInterests(c.Request.Context(), req.Interests)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to validate interests"})
+			return
+		}
+		if !valid {
 			c.JSON(http.StatusBadRequest, model.ErrorResponse{
 				Message: "Unknown interest provided in profile data",
 			})
@@ -235,7 +283,7 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 		}
 	}
 
-	profile, err := h.profileService.UpdateProfileByUserID(user.ID, data)
+	profile, err := h.profileService.UpdateProfileByUserID(c.Request.Context(), user.ID, data)
 	if err != nil {
 		h.logger.Error("failed to update profile", zap.Error(err), zap.String("user_id", user.ID.String()))
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to update profile"})
@@ -272,7 +320,7 @@ func (h *ProfileHandler) UploadProfilePhoto(c *gin.Context) {
 		return
 	}
 
-	if _, err := h.profileService.GetProfileByUserID(user.ID); err != nil {
+	if _, err := h.profileService.GetProfileByUserID(c.Request.Context(), user.ID); err != nil {
 		c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "Profile not found"})
 		return
 	}
@@ -290,7 +338,7 @@ func (h *ProfileHandler) UploadProfilePhoto(c *gin.Context) {
 	}
 
 	// update db with details
-	profile, err := h.profileService.UpdateProfileByUserID(user.ID, &model.Profile{PhotoURL: &photoURL, PhotoPublicID: &publicID})
+	profile, err := h.profileService.UpdateProfileByUserID(c.Request.Context(), user.ID, &model.Profile{PhotoURL: &photoURL, PhotoPublicID: &publicID})
 	if err != nil {
 		h.logger.Error("failed to update profile",
 			zap.Error(err),
@@ -31,7 +31,7 @@ func NewSwipeHandler(swipeService *service.SwipeService, logger *logger.Logger)
 // @Security BearerAuth
 // @Param request body model.CreateSwipeRequest true "Swipe data"
 // @Success 201 {object} model.SuccessResponse{data=model.SwipeResponse} "Swipe created successfully"
-// @Failure 400,401,500 {object} model.ErrorResponse
+// @Failure 400,401,429,500 {object} model.ErrorResponse
 // @Router /swipes [post]
 func (h *SwipeHandler) CreateSwipe(c *gin.Context) {
 	var req model.CreateSwipeRequest
@@ -52,29 +52,37 @@ func (h *SwipeHandler) CreateSwipe(c *gin.Context) {
 		SwipeType: req.SwipeType,
 	}
 
-	swipe, match, err := h.swipeService.CreateSwipe(swipe)
+	swipe, match, err := h.swipeService.CreateSwipe(c.Request.Context(), swipe)
 	if err != nil {
 		if err == service.ErrAlreadySwiped || err == service.ErrSelfSwipe {
 			c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: err.Error()})
 			return
 		}
+		if err == service.ErrLikeLimitReached {
+			c.JSON(http.StatusTooManyRequests, model.ErrorResponse{Message: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to create swipe"})
 		return
 	}
 
 	// send notification on match creation to the profile that was matched
 	if match != nil {
-		h.logger.Info("Match created, sending notification to matched party")
+		h.logger.Info("Match created, sending notification to matched party", logger.RequestIDField(c.Request.Context()))
 		// get swipe details with user preloaded
-		swipe, err = h.swipeService.GetSwipeByID(swipe.ID)
+		swipe, err = h.swipeService.GetSwipeByID(c.Request.Context(), swipe.ID)
 		if err == nil {
-			if err := h.swipeService.SendMatchNotification(swipe); err != nil {
-				h.logger.Error("Failed to send match notification", zap.Error(err))
+			if err := h.swipeService.SendMatchNotification(c.Request.Context(), swipe); err != nil {
+				h.logger.Error("Failed to send match notification", zap.Error(err), logger.RequestIDField(c.Request.Context()))
 			}
 		} else {
 			h.logger.Error("Failed to get swipe details for sending match notification", zap.String("swiper_id", swipe.SwiperID.String()), zap.String("swipee_id", swipe.SwipeeID.String()), zap.Error(err))
 		}
 
+	} else if swipe.SwipeType == model.Like {
+		if err := h.swipeService.SendLikeNotification(c.Request.Context(), swipe); err != nil {
+			h.logger.Error("Failed to send like notification", zap.Error(err), logger.RequestIDField(c.Request.Context()))
+		}
 	}
 
 	c.JSON(http.StatusCreated, model.SuccessResponse{
@@ -110,7 +118,7 @@ func (h *SwipeHandler) GetUserSwipeHistory(c *gin.Context) {
 		return
 	}
 
-	swipes, err := h.swipeService.GetSwipeHistory(user.ID, query.Limit, query.Offset)
+	swipes, err := h.swipeService.GetSwipeHistory(c.Request.Context(), user.ID, query.Limit, query.Offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get swipe history for user"})
 		return
@@ -118,3 +126,74 @@ func (h *SwipeHandler) GetUserSwipeHistory(c *gin.Context) {
 
 	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Swipe history retrieved successfully", Data: swipes})
 }
+
+// GetLikesReceived godoc
+// @Summary Get likes received
+// @Description Get the users who liked the current user and have not been swiped on yet. Premium only
+// @Tags swipes
+// @Produce json
+// @Security BearerAuth
+// @Param limit query number false "Limit" default(20)
+// @Param offset query number false "Offset" default(0)
+// @Success 200 {object} model.SuccessResponse{data=[]model.Swipe} "Likes retrieved successfully"
+// @Failure 400,401,402,500 {object} model.ErrorResponse
+// @Router /swipes/likes [get]
+func (h *SwipeHandler) GetLikesReceived(c *gin.Context) {
+	var query model.PaginationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid query parameters", Detail: err.Error()})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	swipes, err := h.swipeService.GetLikesReceived(c.Request.Context(), user.ID, query.Limit, query.Offset)
+	if err != nil {
+		if err == service.ErrPremiumRequired {
+			c.JSON(http.StatusPaymentRequired, model.ErrorResponse{Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to get likes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Likes retrieved successfully", Data: swipes})
+}
+
+// RewindSwipe godoc
+// @Summary Rewind last swipe
+// @Description Undo the most recent swipe of the current user so the profile can be swiped on again. Swipes that created a match cannot be rewound. Premium only
+// @Tags swipes
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.SuccessResponse{data=model.Swipe} "Swipe rewound successfully"
+// @Failure 401,402,404,409,500 {object} model.ErrorResponse
+// @Router /swipes/rewind [post]
+func (h *SwipeHandler) RewindSwipe(c *gin.Context) {
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	swipe, err := h.swipeService.RewindLastSwipe(c.Request.Context(), user.ID)
+	if err != nil {
+		switch err {
+		case service.ErrPremiumRequired:
+			c.JSON(http.StatusPaymentRequired, model.ErrorResponse{Message: err.Error()})
+		case service.ErrSwipeNotFound:
+			c.JSON(http.StatusNotFound, model.ErrorResponse{Message: "No swipe to rewind"})
+		case service.ErrRewindMatched:
+			c.JSON(http.StatusConflict, model.ErrorResponse{Message: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to rewind swipe"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Swipe rewound successfully", Data: swipe})
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"konnect/internal/cache"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/service"
+	"konnect/internal/util"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type UserHandler struct {
+	authService              *service.AuthService
+	phoneVerificationService *service.PhoneVerificationService
+	logger                   *zap.Logger
+}
+
+func NewUserHandler(authService *service.AuthService, phoneVerificationService *service.PhoneVerificationService, logger *logger.Logger) *UserHandler {
+	return &UserHandler{
+		authService:              authService,
+		phoneVerificationService: phoneVerificationService,
+		logger:                   logger.With(zap.String("component", "user_handler")),
+	}
+}
+
+// UpdatePhoneNumber godoc
+// @Summary Update phone number
+// @Description Set the phone number of the authenticated user and send a verification code by sms. Local numbers are normalized to E.164 with the default country code. The number is updated even when a code was sent recently, the code is then requested again after the cooldown in the 429 response
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.UpdatePhoneNumberRequest true "Phone number"
+// @Success 200 {object} model.SuccessResponse{data=model.User} "Phone number updated successfully"
+// @Failure 400,401,409,500 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse{detail=model.OTPCooldownDetail} "Verification code sent recently"
+// @Router /users/me/phone [put]
+func (h *UserHandler) UpdatePhoneNumber(c *gin.Context) {
+	var req model.UpdatePhoneNumberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid phone number", Detail: err.Error()})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	updatedUser, err := h.authService.UpdatePhoneNumber(c.Request.Context(), user.ID, req.PhoneNumber)
+	if err != nil {
+		switch err {
+		case util.ErrInvalidPhoneNumber:
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid phone number"})
+		case service.ErrPhoneTaken:
+			c.JSON(http.StatusConflict, model.ErrorResponse{Message: err.Error()})
+		default:
+			h.logger.Error("failed to update phone number", zap.Error(err), zap.String("user_id", user.ID.String()))
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to update phone number"})
+		}
+		return
+	}
+
+	// unchanged verified numbers do not need a new code
+	if !updatedUser.PhoneVerified {
+		if err := h.phoneVerificationService.SendOTP(c.Request.Context(), user.ID); err != nil {
+			if err == cache.ErrOTPCooldown {
+				h.otpCooldown(c, user.ID, "Phone number updated, a verification code was sent recently. Request a new one after the cooldown")
+				return
+			}
+			h.logger.Error("failed to send phone verification code", zap.Error(err), zap.String("user_id", user.ID.String()))
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to send verification code"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Phone number updated successfully", Data: updatedUser})
+}
+
+// ResendPhoneVerificationCode godoc
+// @Summary Resend phone verification code
+// @Description Send a new verification code to the unverified phone number of the authenticated user
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.SuccessResponse "Verification code sent successfully"
+// @Failure 400,401,500 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse{detail=model.OTPCooldownDetail} "Verification code sent recently"
+// @Router /users/me/phone/resend [post]
+func (h *UserHandler) ResendPhoneVerificationCode(c *gin.Context) {
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	if err := h.phoneVerificationService.SendOTP(c.Request.Context(), user.ID); err != nil {
+		switch err {
+		case service.ErrPhoneNotSet, service.ErrPhoneAlreadyVerified:
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: err.Error()})
+		case cache.ErrOTPCooldown:
+			h.otpCooldown(c, user.ID, err.Error())
+		default:
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to send verification code"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Verification code sent successfully"})
+}
+
+// VerifyPhoneNumber godoc
+// @Summary Verify phone number
+// @Description Verify the phone number of the authenticated user with the code sent by sms. A new token carrying the phoneVerified claim is returned
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.VerifyPhoneNumberRequest true "Verification code"
+// @Success 200 {object} model.SuccessResponse{data=model.AuthResponse} "Phone number verified successfully"
+// @Failure 400,401,409,429,500 {object} model.ErrorResponse
+// @Router /users/me/phone/verify [post]
+func (h *UserHandler) VerifyPhoneNumber(c *gin.Context) {
+	var req model.VerifyPhoneNumberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid verification code", Detail: err.Error()})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	if err := h.phoneVerificationService.VerifyOTP(c.Request.Context(), user.ID, req.Code); err != nil {
+		switch err {
+		case cache.ErrOTPExpired, cache.ErrOTPInvalid:
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: err.Error()})
+		case cache.ErrOTPAttemptsExceeded:
+			c.JSON(http.StatusTooManyRequests, model.ErrorResponse{Message: err.Error()})
+		case service.ErrPhoneChanged:
+			c.JSON(http.StatusConflict, model.ErrorResponse{Message: err.Error()})
+		default:
+			h.logger.Error("failed to verify phone number", zap.Error(err), zap.String("user_id", user.ID.String()))
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to verify phone number"})
+		}
+		return
+	}
+
+	// issue a token with the updated claims
+	verifiedUser, err := h.authService.GetUserByID(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to verify phone number"})
+		return
+	}
+	token, err := h.authService.GenerateAccessToken(c.Request.Context(), verifiedUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to generate access token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Phone number verified successfully", Data: model.AuthResponse{
+		Token: token,
+		User:  *verifiedUser,
+	}})
+}
+
+// UpdateLocale godoc
+// @Summary Update locale
+// @Description Set the preferred language used for the authenticated user's notifications. Unsupported languages fall back to English
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.UpdateLocaleRequest true "Locale"
+// @Success 200 {object} model.SuccessResponse{data=model.User} "Locale updated successfully"
+// @Failure 400,401,500 {object} model.ErrorResponse
+// @Router /users/me/locale [put]
+func (h *UserHandler) UpdateLocale(c *gin.Context) {
+	var req model.UpdateLocaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid locale", Detail: err.Error()})
+		return
+	}
+
+	user, ok := GetCurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		return
+	}
+
+	updatedUser, err := h.authService.UpdateLocale(c.Request.Context(), user.ID, req.Locale)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to update locale"})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Locale updated successfully", Data: updatedUser})
+}
+
+// otpCooldown responds with the time left before a new verification code can be sent
+func (h *UserHandler) otpCooldown(c *gin.Context, userID uuid.UUID, message string) {
+	retryAfter := int(math.Ceil(h.phoneVerificationService.OTPCooldown(c.Request.Context(), userID).Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, model.ErrorResponse{Message: message, Detail: model.OTPCooldownDetail{RetryAfterSeconds: retryAfter}})
+}
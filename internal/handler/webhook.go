@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"io"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxWebhookBodySize bounds the webhook bodies read into memory
+const maxWebhookBodySize = 1 << 20
+
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+	logger         *zap.Logger
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService, logger *logger.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		logger:         logger.With(zap.String("component", "webhook_handler")),
+	}
+}
+
+// PaystackWebhook godoc
+// @Summary Paystack webhook
+// @Description Receive paystack events. Events are verified with the x-paystack-signature header and the source ip, stored and processed in the background
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param x-paystack-signature header string true "HMAC-SHA512 of the body signed with the paystack secret key"
+// @Success 200 {object} model.SuccessResponse "Event received"
+// @Failure 400,401,500 {object} model.ErrorResponse
+// @Router /webhooks/paystack [post]
+func (h *WebhookHandler) PaystackWebhook(c *gin.Context) {
+	// the signature covers the raw body
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodySize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: "Invalid webhook body"})
+		return
+	}
+
+	err = h.webhookService.ReceivePaystackEvent(c.Request.Context(), payload, c.GetHeader("x-paystack-signature"), c.ClientIP())
+	if err != nil {
+		switch err {
+		case service.ErrInvalidWebhookOrigin, service.ErrInvalidWebhookSignature:
+			h.logger.Warn("rejected paystack webhook", zap.String("ip", c.ClientIP()), zap.Error(err))
+			c.JSON(http.StatusUnauthorized, model.ErrorResponse{Message: "Unauthorized"})
+		case service.ErrInvalidWebhookPayload:
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{Message: err.Error()})
+		default:
+			// paystack redelivers events that were not acknowledged
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{Message: "Failed to receive event"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{Message: "Event received"})
+}
@@ -0,0 +1,187 @@
+// Package health checks the dependencies of the api and the worker for readiness probes
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"konnect/internal/model"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+var ErrTimeout = errors.New("health check timed out")
+
+// DefaultTimeout is how long a component has to answer a check
+const DefaultTimeout = 2 * time.Second
+
+// Component is a dependency checked for readiness
+type Component struct {
+	Name string
+	// optional components only degrade the service when they are down
+	Optional bool
+	Check    func(ctx context.Context) error
+}
+
+// Checker checks components concurrently, each within the timeout
+type Checker struct {
+	components []Component
+	timeout    time.Duration
+}
+
+func NewChecker(timeout time.Duration, components ...Component) *Checker {
+	return &Checker{
+		components: components,
+		timeout:    timeout,
+	}
+}
+
+// Check reports the status of every component. The service is unavailable when a required component is down
+func (c *Checker) Check(ctx context.Context) model.HealthResponse {
+	results := make([]model.ComponentHealth, len(c.components))
+
+	var wg sync.WaitGroup
+	for i, component := range c.components {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.check(ctx, component)
+		}()
+	}
+	wg.Wait()
+
+	resp := model.HealthResponse{Status: model.HealthOK, Components: make(map[string]model.ComponentHealth, len(results))}
+	for i, result := range results {
+		resp.Components[c.components[i].Name] = result
+		if result.Status == model.ComponentUp {
+			continue
+		}
+		if !result.Optional {
+			resp.Status = model.HealthUnavailable
+		} else if resp.Status == model.HealthOK {
+			resp.Status = model.HealthDegraded
+		}
+	}
+	return resp
+}
+
+func (c *Checker) check(ctx context.Context, component Component) model.ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	// checks that ignore the context are abandoned once it is done
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- component.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ErrTimeout
+	}
+
+	result := model.ComponentHealth{Status: model.ComponentUp, Optional: component.Optional, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = model.ComponentDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LiveHandler answers liveness probes of servers without a router. The process is live as long as it serves them
+func LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, model.SuccessResponse{Message: "Service live", Data: model.HealthResponse{Status: model.HealthOK}})
+	})
+}
+
+// ReadyHandler answers readiness probes of servers without a router with the status of every component
+func (c *Checker) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := c.Check(r.Context())
+		if resp.Status == model.HealthUnavailable {
+			writeJSON(w, http.StatusServiceUnavailable, model.ErrorResponse{Message: "Service unavailable", Detail: resp})
+			return
+		}
+		writeJSON(w, http.StatusOK, model.SuccessResponse{Message: "Service ready", Data: resp})
+	})
+}
+
+// Postgres pings the database
+func Postgres(db *gorm.DB) Component {
+	return Component{
+		Name: "postgres",
+		Check: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// Redis pings the cache
+func Redis(client *redis.Client) Component {
+	return Component{
+		Name: "redis",
+		Check: func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		},
+	}
+}
+
+// Broker pings the redis server of the task queues through a worker client or server
+func Broker(client interface{ Ping() error }) Component {
+	return Component{
+		Name: "broker",
+		Check: func(ctx context.Context) error {
+			return client.Ping()
+		},
+	}
+}
+
+// Dial checks that a tcp connection can be opened to the address, for dependencies without a ping, e.g. smtp servers
+func Dial(name, addr string, optional bool) Component {
+	return Component{
+		Name:     name,
+		Optional: optional,
+		Check: func(ctx context.Context) error {
+			var dialer net.Dialer
+			conn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		},
+	}
+}
+
+// DialURL checks that a tcp connection can be opened to the host of an http url, e.g. a payment provider api
+func DialURL(name, rawURL string, optional bool) (Component, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Component{}, err
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return Dial(name, net.JoinHostPort(u.Hostname(), port), optional), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type contextKey string
+
+const requestIDKey contextKey = "request_id"
+
+// WithRequestID returns a copy of ctx carrying the id of the request it belongs to
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request id carried by ctx, empty outside of requests
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// RequestIDField is the log field of the request id carried by ctx. It is skipped outside of requests
+func RequestIDField(ctx context.Context) zap.Field {
+	if id := RequestID(ctx); id != "" {
+		return zap.String("request_id", id)
+	}
+	return zap.Skip()
+}
+
+// FromContext returns l with the request id carried by ctx attached to every entry
+func FromContext(ctx context.Context, l *zap.Logger) *zap.Logger {
+	if id := RequestID(ctx); id != "" {
+		return l.With(zap.String("request_id", id))
+	}
+	return l
+}
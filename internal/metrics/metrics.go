@@ -0,0 +1,112 @@
+// Package metrics holds the prometheus metrics of the api and the worker. They are registered with the default registry
+// and exposed on /metrics by both processes, on ports kept off the public network
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+const namespace = "konnect"
+
+var (
+	// HTTPRequestDuration is the latency of api requests by route and status
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of http requests by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// SwipesTotal counts created swipes by type
+	SwipesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "swipes_total",
+		Help:      "Swipes created by swipe type.",
+	}, []string{"type"})
+
+	// MatchesTotal counts created matches
+	MatchesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "matches_total",
+		Help:      "Matches created.",
+	})
+
+	// CachePipelineDuration is the latency of redis pipelines by cache and operation
+	CachePipelineDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "pipeline_duration_seconds",
+		Help:      "Latency of redis pipelines by cache, operation and result.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"cache", "operation", "result"})
+
+	// CacheSyncsTotal counts profile syncs into the interest cache and location index by result
+	CacheSyncsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "syncs_total",
+		Help:      "Profile syncs into the interest cache and location index by result.",
+	}, []string{"result"})
+
+	// CacheSyncEnqueueFailures counts profile syncs that could not be enqueued
+	CacheSyncEnqueueFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "sync_enqueue_failures_total",
+		Help:      "Profile syncs that could not be enqueued.",
+	})
+
+	// CacheSyncLag is the time from a profile change in the database to its sync into the cache
+	CacheSyncLag = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "sync_lag_seconds",
+		Help:      "Time from a profile change in the database to its sync into the cache.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300, 900},
+	})
+
+	// TasksProcessed counts processed worker tasks by type and result
+	TasksProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "worker",
+		Name:      "tasks_processed_total",
+		Help:      "Worker tasks processed by type and result.",
+	}, []string{"type", "result"})
+
+	// TaskDuration is the processing time of worker tasks by type
+	TaskDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "worker",
+		Name:      "task_duration_seconds",
+		Help:      "Processing time of worker tasks by type.",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"type"})
+)
+
+// ObservePipeline records the latency of a redis pipeline that started at start
+func ObservePipeline(cache, operation string, start time.Time, err error) {
+	CachePipelineDuration.WithLabelValues(cache, operation, Result(err)).Observe(time.Since(start).Seconds())
+}
+
+// Result is the result label of an operation, success or failure
+func Result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// RegisterDB exposes the connection pool stats of the database, e.g. open, in use and idle connections and waits
+func RegisterDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return prometheus.Register(collectors.NewDBStatsCollector(sqlDB, "postgres"))
+}
@@ -0,0 +1,150 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Feature is a premium feature unlocked by an active subscription
+type Feature string
+
+const (
+	FeatureUnlimitedLikes Feature = "unlimited_likes"
+	FeatureSeeLikes       Feature = "see_who_liked_you"
+	FeatureRewind         Feature = "rewind"
+)
+
+// PremiumFeatures are the features of every plan
+var PremiumFeatures = []Feature{FeatureUnlimitedLikes, FeatureSeeLikes, FeatureRewind}
+
+type PlanKind string
+
+const (
+	SubscriptionPlan PlanKind = "subscription"
+	// a one-off visibility boost
+	BoostPlan PlanKind = "boost"
+)
+
+// Plan is a premium subscription or a boost users can buy. Each subscription payment extends the subscription by the
+// plan duration, each boost payment schedules a boost
+type Plan struct {
+	Model
+	Code string   `gorm:"type:varchar(50);not null;uniqueIndex" json:"code"`
+	Name string   `gorm:"type:varchar(100);not null" json:"name"`
+	Kind PlanKind `gorm:"type:varchar(20);not null;default:'subscription'" json:"kind"`
+	// price in the minor unit of the currency, e.g. pesewas
+	Amount       int64  `gorm:"not null" json:"amount"`
+	Currency     string `gorm:"type:varchar(3);not null;default:'GHS'" json:"currency"`
+	DurationDays int    `gorm:"not null" json:"durationDays"`
+	// length of a boost
+	BoostMinutes int  `gorm:"not null;default:0" json:"boostMinutes,omitempty"`
+	Active       bool `gorm:"not null;default:true" json:"active"`
+	// plan on paystack that renews the subscription automatically. Plans without one are paid once per period
+	PaystackPlanCode *string `gorm:"type:varchar(100);uniqueIndex" json:"-"`
+}
+
+// Subscription is the premium access of a user. It is active until it expires
+type Subscription struct {
+	Model
+	UserID             uuid.UUID `gorm:"not null;uniqueIndex" json:"userId"`
+	PlanID             uuid.UUID `gorm:"not null" json:"planId"`
+	CurrentPeriodStart time.Time `gorm:"not null" json:"currentPeriodStart"`
+	ExpiresAt          time.Time `gorm:"not null;index" json:"expiresAt"`
+	// set while paystack renews the subscription automatically
+	AutoRenew                bool    `gorm:"not null;default:false" json:"autoRenew"`
+	PaystackSubscriptionCode *string `gorm:"type:varchar(100)" json:"-"`
+
+	// relations
+	Plan *Plan `json:"plan,omitempty"`
+}
+
+// Active reports whether the subscription grants premium access at t
+func (s *Subscription) Active(t time.Time) bool {
+	return s.ExpiresAt.After(t)
+}
+
+type PaymentStatus string
+
+const (
+	PaymentPending   PaymentStatus = "pending"
+	PaymentSuccess   PaymentStatus = "success"
+	PaymentFailed    PaymentStatus = "failed"
+	PaymentAbandoned PaymentStatus = "abandoned"
+	PaymentReversed  PaymentStatus = "reversed"
+)
+
+type PaymentChannel string
+
+const (
+	// paid on the paystack checkout page
+	CheckoutChannel    PaymentChannel = "checkout"
+	MobileMoneyChannel PaymentChannel = "mobile_money"
+	// charged by paystack for a recurring subscription
+	RenewalChannel PaymentChannel = "renewal"
+)
+
+// Payment is a paystack transaction for a plan, identified by its reference
+type Payment struct {
+	Model
+	UserID    uuid.UUID      `gorm:"not null;index" json:"userId"`
+	PlanID    uuid.UUID      `gorm:"not null" json:"planId"`
+	Reference string         `gorm:"type:varchar(100);not null;uniqueIndex" json:"reference"`
+	Amount    int64          `gorm:"not null" json:"amount"`
+	Currency  string         `gorm:"type:varchar(3);not null" json:"currency"`
+	Channel   PaymentChannel `gorm:"type:varchar(20);not null" json:"channel"`
+	Status    PaymentStatus  `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	// last message from the payment provider, e.g. Approved
+	GatewayResponse string     `gorm:"type:varchar(255)" json:"gatewayResponse"`
+	PaidAt          *time.Time `json:"paidAt"`
+
+	// relations
+	Plan *Plan `json:"plan,omitempty"`
+}
+
+// Entitlements are the features available to a user
+type Entitlements struct {
+	Premium   bool       `json:"premium"`
+	Features  []Feature  `json:"features"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// likes allowed per 24 hours, 0 means unlimited
+	DailyLikeLimit int `json:"dailyLikeLimit"`
+}
+
+type CheckoutRequest struct {
+	PlanCode string `json:"planCode" binding:"required,max=50"`
+}
+
+type CheckoutResponse struct {
+	Reference string `json:"reference"`
+	// paystack checkout page the user completes the payment on
+	AuthorizationURL string `json:"authorizationUrl"`
+	AccessCode       string `json:"accessCode"`
+}
+
+type SubscriptionResponse struct {
+	Subscription *Subscription `json:"subscription"`
+	Entitlements Entitlements  `json:"entitlements"`
+}
+
+// params with only a payment reference
+type ReferenceParam struct {
+	Reference string `uri:"reference" binding:"required,max=100"`
+}
+
+type MobileMoneyChargeRequest struct {
+	PlanCode string `json:"planCode" binding:"required,max=50"`
+}
+
+type SubmitOTPRequest struct {
+	OTP string `json:"otp" binding:"required,numeric,max=10"`
+}
+
+// MobileMoneyChargeResponse tells the client what the customer has to do next. send_otp asks for the otp sent to the
+// customer, pay_offline for an approval on their phone
+type MobileMoneyChargeResponse struct {
+	Reference   string               `json:"reference"`
+	Provider    PaystackProviderCode `json:"provider,omitempty"`
+	Status      PaystackStatus       `json:"status"`
+	DisplayText string               `json:"displayText,omitempty"`
+}
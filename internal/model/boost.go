@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Boost ranks a user higher in nearby results while it runs. A boost bought while another is running starts when it ends
+type Boost struct {
+	Model
+	UserID    uuid.UUID `gorm:"not null;index" json:"userId"`
+	PaymentID uuid.UUID `gorm:"not null;uniqueIndex" json:"paymentId"`
+	StartsAt  time.Time `gorm:"not null" json:"startsAt"`
+	EndsAt    time.Time `gorm:"not null;index" json:"endsAt"`
+	// performance during the boost, final once the boost has expired
+	Views         int64 `gorm:"not null;default:0" json:"views"`
+	LikesReceived int64 `gorm:"not null;default:0" json:"likesReceived"`
+	Expired       bool  `gorm:"not null;default:false;index" json:"expired"`
+}
+
+// Active reports whether the boost is running at t
+func (b *Boost) Active(t time.Time) bool {
+	return !t.Before(b.StartsAt) && t.Before(b.EndsAt)
+}
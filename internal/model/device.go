@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DevicePlatform string
+
+const (
+	Android DevicePlatform = "android"
+	IOS     DevicePlatform = "ios"
+	Web     DevicePlatform = "web"
+)
+
+type DeviceToken struct {
+	Model
+	UserID   uuid.UUID      `gorm:"not null;index" json:"userId"`
+	Token    string         `gorm:"type:varchar(512);not null;uniqueIndex" json:"token"`
+	Platform DevicePlatform `gorm:"type:varchar(20);not null" json:"platform"`
+	// last time the device was registered by the app
+	LastSeenAt time.Time `gorm:"not null;default:now()" json:"lastSeenAt"`
+
+	// relations
+	User *User `json:"user,omitempty"`
+}
+
+type RegisterDeviceRequest struct {
+	Token    string         `json:"token" binding:"required,max=512"`
+	Platform DevicePlatform `json:"platform" binding:"required,oneof=android ios web"`
+}
+
+type UnregisterDeviceRequest struct {
+	Token string `json:"token" binding:"required,max=512"`
+}
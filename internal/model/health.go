@@ -0,0 +1,32 @@
+package model
+
+type HealthStatus string
+
+const (
+	// every dependency is up
+	HealthOK HealthStatus = "ok"
+	// required dependencies are up, some optional ones are down
+	HealthDegraded HealthStatus = "degraded"
+	// a required dependency is down
+	HealthUnavailable HealthStatus = "unavailable"
+)
+
+type ComponentStatus string
+
+const (
+	ComponentUp   ComponentStatus = "up"
+	ComponentDown ComponentStatus = "down"
+)
+
+type ComponentHealth struct {
+	Status ComponentStatus `json:"status"`
+	// optional components do not make the service unavailable when they are down
+	Optional  bool   `json:"optional,omitempty"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+type HealthResponse struct {
+	Status     HealthStatus               `json:"status"`
+	Components map[string]ComponentHealth `json:"components,omitempty"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProfileImpression is the estimated number of distinct users a profile was shown to on a day, flushed from redis
+type ProfileImpression struct {
+	Model
+	UserID  uuid.UUID `gorm:"not null;uniqueIndex:idx_profile_impressions_user_day" json:"userId"`
+	Day     time.Time `gorm:"type:date;not null;uniqueIndex:idx_profile_impressions_user_day" json:"day"`
+	Viewers int64     `gorm:"not null;default:0" json:"viewers"`
+}
+
+type ProfileViewsQuery struct {
+	// viewers are kept in redis for a week
+	Days int `form:"days,default=7" binding:"min=1,max=7"`
+}
+
+type ProfileViewsResponse struct {
+	Days int `json:"days"`
+	// distinct users the profile was shown to, an estimate
+	Viewers int64 `json:"viewers"`
+}
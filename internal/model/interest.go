@@ -0,0 +1,140 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type InterestCategory string
+
+const (
+	ArtsInterests          InterestCategory = "arts"
+	EntertainmentInterests InterestCategory = "entertainment"
+	FoodInterests          InterestCategory = "food"
+	MusicInterests         InterestCategory = "music"
+	OutdoorsInterests      InterestCategory = "outdoors"
+	SportsInterests        InterestCategory = "sports"
+	LifestyleInterests     InterestCategory = "lifestyle"
+	SocialInterests        InterestCategory = "social"
+	CareerInterests        InterestCategory = "career"
+)
+
+// InterestCategories lists the categories in display order
+var InterestCategories = []InterestCategory{
+	ArtsInterests, EntertainmentInterests, FoodInterests, MusicInterests, OutdoorsInterests,
+	SportsInterests, LifestyleInterests, SocialInterests, CareerInterests,
+}
+
+// InterestLabels is a custom type for handling postgres JSONB interest names keyed by locale
+type InterestLabels map[string]string
+
+// Scan implements sql.Scanner interface for gorm capatibility
+func (l *InterestLabels) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// Value implements driver.Valuer interface for gorm compatibility
+func (l InterestLabels) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+type Interest struct {
+	Model
+	Slug string `gorm:"type:varchar(100);not null;uniqueIndex" json:"slug"`
+	// display name, stored in profiles and used as the redis bucket name
+	Name     string           `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"`
+	Category InterestCategory `gorm:"type:varchar(50);not null;index" json:"category"`
+	// retired interests cannot be picked anymore, profiles that have them keep them
+	Active bool           `gorm:"not null;default:true" json:"active"`
+	Labels InterestLabels `gorm:"type:jsonb" json:"labels,omitempty"`
+	// set when the interest was merged into another one
+	MergedIntoID *uuid.UUID `gorm:"type:uuid" json:"mergedIntoId,omitempty"`
+}
+
+// Label returns the name of the interest in a locale. Regional locales fall back to their base language and then the display name
+func (i *Interest) Label(locale string) string {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	base, _, _ := strings.Cut(locale, "-")
+	for _, candidate := range []string{locale, base} {
+		if label, ok := i.Labels[candidate]; ok && label != "" {
+			return label
+		}
+	}
+	return i.Name
+}
+
+type InterestResponse struct {
+	ID    uuid.UUID `json:"id"`
+	Slug  string    `json:"slug"`
+	Name  string    `json:"name"`
+	Label string    `json:"label"`
+}
+
+type InterestCategoryResponse struct {
+	Category  InterestCategory   `json:"category"`
+	Interests []InterestResponse `json:"interests"`
+}
+
+type GetInterestsQuery struct {
+	Locale string `form:"locale" binding:"omitempty,max=10"`
+}
+
+type CreateInterestRequest struct {
+	Name     string           `json:"name" binding:"required,min=2,max=100"`
+	Slug     string           `json:"slug" binding:"omitempty,max=100"`
+	Category InterestCategory `json:"category" binding:"required,oneof=arts entertainment food music outdoors sports lifestyle social career"`
+	Labels   InterestLabels   `json:"labels"`
+}
+
+type UpdateInterestRequest struct {
+	Category *InterestCategory `json:"category,omitempty" binding:"omitempty,oneof=arts entertainment food music outdoors sports lifestyle social career"`
+	Labels   InterestLabels    `json:"labels,omitempty"`
+	Active   *bool             `json:"active,omitempty"`
+}
+
+type MergeInterestRequest struct {
+	// interest the source is merged into
+	TargetID uuid.UUID `json:"targetId" binding:"required"`
+}
+
+type MergeInterestResponse struct {
+	Target           Interest `json:"target"`
+	ProfilesMigrated int64    `json:"profilesMigrated"`
+}
+
+// InterestStatsQuery counts members across all users, or within radius meters of the coordinates when they are given
+type InterestStatsQuery struct {
+	Lat    *float64 `form:"lat" binding:"required_with=Lng,omitempty,latitude"`
+	Lng    *float64 `form:"lng" binding:"required_with=Lat,omitempty,longitude"`
+	Radius float64  `form:"radius,default=5000" binding:"min=100,max=50000"`
+	Locale string   `form:"locale" binding:"omitempty,max=10"`
+}
+
+type InterestStat struct {
+	InterestResponse
+	Category InterestCategory `json:"category"`
+	Members  int64            `json:"members"`
+}
+
+type TrendingInterestsQuery struct {
+	// number of days back additions are counted
+	Days   int    `form:"days,default=7" binding:"min=1,max=30"`
+	Limit  int    `form:"limit,default=10" binding:"min=1,max=50"`
+	Locale string `form:"locale" binding:"omitempty,max=10"`
+}
+
+type TrendingInterest struct {
+	InterestResponse
+	Category  InterestCategory `json:"category"`
+	Additions int64            `json:"additions"`
+}
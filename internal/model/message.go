@@ -1,12 +1,19 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Message struct {
 	Model
-	MatchID  uuid.UUID `gorm:"not null" json:"matchId"`
+	// match ids are the sorted user ids of the match
+	MatchID  string    `gorm:"type:varchar(255);not null;index" json:"matchId"`
 	SenderID uuid.UUID `gorm:"not null" json:"senderId"`
 	Content  string    `gorm:"type:varchar(5000);not null" json:"content"`
+	// set once the recipient read the message
+	ReadAt *time.Time `json:"readAt"`
 
 	// relations
 	Match  *Match `json:"match,omitempty"`
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationType string
+
+const (
+	MatchNotification   NotificationType = "match"
+	MessageNotification NotificationType = "message"
+	LikeNotification    NotificationType = "like"
+	// the user's phone number was verified
+	VerificationNotification NotificationType = "verification"
+	// summary of likes, matches and messages for users in digest mode. Only sent by email
+	DigestNotification NotificationType = "digest"
+)
+
+// NotificationData is a custom type for handling postgres JSONB notification metadata
+type NotificationData map[string]string
+
+// Scan implements sql.Scanner interface for gorm capatibility
+func (d *NotificationData) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, d)
+}
+
+// Value implements driver.Valuer interface for gorm compatibility
+func (d NotificationData) Value() (driver.Value, error) {
+	if len(d) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(d)
+}
+
+type Notification struct {
+	Model
+	UserID uuid.UUID        `gorm:"not null;index" json:"userId"`
+	Type   NotificationType `gorm:"type:varchar(50);not null" json:"type"`
+	Title  string           `gorm:"type:varchar(255);not null" json:"title"`
+	Body   string           `gorm:"type:varchar(1000);not null" json:"body"`
+	Data   NotificationData `gorm:"type:jsonb" json:"data,omitempty"`
+	// nil until the user opens the notification
+	ReadAt *time.Time `json:"readAt"`
+
+	// relations
+	User *User `json:"user,omitempty"`
+}
+
+type GetNotificationsQuery struct {
+	UnreadOnly bool `form:"unreadOnly,default=false"`
+	Limit      int  `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset     int  `form:"offset,default=0" binding:"min=0"`
+}
+
+type NotificationsResponse struct {
+	Notifications []Notification `json:"notifications"`
+	UnreadCount   int64          `json:"unreadCount"`
+}
+
+type MarkAllNotificationsReadResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+type PreviewEmailTemplateRequest struct {
+	Locale string         `json:"locale" binding:"omitempty,max=10"`
+	Data   map[string]any `json:"data"`
+}
+
+// template ids in the uri
+type TemplateIDParam struct {
+	ID string `uri:"id" binding:"required,max=100"`
+}
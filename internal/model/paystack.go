@@ -0,0 +1,159 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PaystackProviderCode is the mobile money network of a charge
+type PaystackProviderCode string
+
+const (
+	MTN        PaystackProviderCode = "mtn"
+	Vodafone   PaystackProviderCode = "vod"
+	AirtelTigo PaystackProviderCode = "atl"
+)
+
+// PaystackStatus is the status of a transaction or charge on paystack
+type PaystackStatus string
+
+const (
+	PaystackSuccess   PaystackStatus = "success"
+	PaystackFailed    PaystackStatus = "failed"
+	PaystackAbandoned PaystackStatus = "abandoned"
+	PaystackReversed  PaystackStatus = "reversed"
+	PaystackPending   PaystackStatus = "pending"
+	PaystackOngoing   PaystackStatus = "ongoing"
+	// the customer must submit the otp sent to their phone
+	PaystackSendOTP PaystackStatus = "send_otp"
+	// the customer must approve the charge on their phone
+	PaystackPayOffline PaystackStatus = "pay_offline"
+)
+
+type PaystackErrorResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+}
+
+// PaystackInitTransfer initializes a transaction paid on the paystack checkout page. Amounts are in the minor unit of the currency
+type PaystackInitTransfer struct {
+	Amount      string  `json:"amount"`
+	Email       string  `json:"email"`
+	Currency    string  `json:"currency,omitempty"`
+	Reference   *string `json:"reference,omitempty"`
+	CallbackURL string  `json:"callback_url,omitempty"`
+	// paystack plan code, makes the transaction start a recurring subscription
+	Plan     string         `json:"plan,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+type PaystackInitTransferResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    struct {
+		AuthorizationURL string `json:"authorization_url"`
+		AccessCode       string `json:"access_code"`
+		Reference        string `json:"reference"`
+	} `json:"data"`
+}
+
+// PaystackTransaction is a transaction as returned by the verify endpoint and webhooks
+type PaystackTransaction struct {
+	ID              int64            `json:"id"`
+	Status          PaystackStatus   `json:"status"`
+	Reference       string           `json:"reference"`
+	Amount          int64            `json:"amount"`
+	Currency        string           `json:"currency"`
+	Channel         string           `json:"channel"`
+	GatewayResponse string           `json:"gateway_response"`
+	PaidAt          *time.Time       `json:"paid_at"`
+	Customer        PaystackCustomer `json:"customer"`
+	// plan of recurring charges. Paystack sends an object, an empty object or the plan code depending on the endpoint
+	Plan json.RawMessage `json:"plan"`
+}
+
+// PlanCode returns the code of the paystack plan the transaction was charged for, empty for one-off transactions
+func (t *PaystackTransaction) PlanCode() string {
+	var code string
+	if err := json.Unmarshal(t.Plan, &code); err == nil {
+		return code
+	}
+	var plan PaystackPlan
+	if err := json.Unmarshal(t.Plan, &plan); err == nil {
+		return plan.PlanCode
+	}
+	return ""
+}
+
+type PaystackCustomer struct {
+	Email        string `json:"email"`
+	CustomerCode string `json:"customer_code"`
+}
+
+type PaystackPlan struct {
+	PlanCode string `json:"plan_code"`
+}
+
+// PaystackSubscription is a recurring paystack subscription as sent by subscription webhooks
+type PaystackSubscription struct {
+	ID               int64            `json:"id"`
+	Status           string           `json:"status"`
+	SubscriptionCode string           `json:"subscription_code"`
+	Plan             PaystackPlan     `json:"plan"`
+	Customer         PaystackCustomer `json:"customer"`
+}
+
+// PaystackEvent is the body of a paystack webhook. Data depends on the event
+type PaystackEvent struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// paystack webhook events
+const (
+	PaystackChargeSuccess        = "charge.success"
+	PaystackSubscriptionCreate   = "subscription.create"
+	PaystackSubscriptionNotRenew = "subscription.not_renew"
+	PaystackSubscriptionDisable  = "subscription.disable"
+)
+
+type PaystackVerifyResponse struct {
+	Status  bool                `json:"status"`
+	Message string              `json:"message"`
+	Data    PaystackTransaction `json:"data"`
+}
+
+// PaystackCreateCharge charges a customer directly without the checkout page, e.g. on mobile money
+type PaystackCreateCharge struct {
+	Amount      int            `json:"amount"`
+	Email       string         `json:"email"`
+	Currency    string         `json:"currency,omitempty"`
+	Reference   *string        `json:"reference,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	MobileMoney struct {
+		Phone    string               `json:"phone"`
+		Provider PaystackProviderCode `json:"provider"`
+	} `json:"mobile_money"`
+}
+
+// PaystackCharge is the state of a direct charge. Pending charges may need an otp or an approval on the customer's phone
+type PaystackCharge struct {
+	Reference       string         `json:"reference"`
+	Status          PaystackStatus `json:"status"`
+	DisplayText     string         `json:"display_text"`
+	Amount          int64          `json:"amount"`
+	Currency        string         `json:"currency"`
+	GatewayResponse string         `json:"gateway_response"`
+}
+
+type PaystackCreateChargeResponse struct {
+	Status  bool           `json:"status"`
+	Message string         `json:"message"`
+	Data    PaystackCharge `json:"data"`
+}
+
+type PaystackSubmitOtpResponse struct {
+	Status  bool           `json:"status"`
+	Message string         `json:"message"`
+	Data    PaystackCharge `json:"data"`
+}
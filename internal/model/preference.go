@@ -0,0 +1,199 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeliveryMode string
+
+const (
+	// InstantDelivery emails every notification as it happens
+	InstantDelivery DeliveryMode = "instant"
+	// DigestDelivery batches notification emails into a periodic digest
+	DigestDelivery DeliveryMode = "digest"
+)
+
+type DigestFrequency string
+
+const (
+	DailyDigest  DigestFrequency = "daily"
+	WeeklyDigest DigestFrequency = "weekly"
+)
+
+// Period returns how far back a digest of this frequency looks
+func (f DigestFrequency) Period() time.Duration {
+	if f == WeeklyDigest {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+type NotificationChannel string
+
+const (
+	EmailChannel NotificationChannel = "email"
+	PushChannel  NotificationChannel = "push"
+)
+
+// quiet hours are wall clock times in the user's timezone, e.g. 22:00
+const QuietHoursLayout = "15:04"
+
+// ConfigurableNotificationTypes are the notification types users can opt out of. Other types are transactional
+var ConfigurableNotificationTypes = []NotificationType{MatchNotification, MessageNotification, LikeNotification}
+
+// Configurable reports whether users can opt out of or defer a notification type
+func (t NotificationType) Configurable() bool {
+	return slices.Contains(ConfigurableNotificationTypes, t)
+}
+
+// ChannelSettings toggles the delivery channels of a notification type. In-app notifications are always recorded
+type ChannelSettings struct {
+	Email bool `json:"email"`
+	Push  bool `json:"push"`
+}
+
+// NotificationChannels is a custom type for handling postgres JSONB channel settings keyed by notification type
+type NotificationChannels map[NotificationType]ChannelSettings
+
+// Scan implements sql.Scanner interface for gorm capatibility
+func (c *NotificationChannels) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+// Value implements driver.Valuer interface for gorm compatibility
+func (c NotificationChannels) Value() (driver.Value, error) {
+	if len(c) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+type NotificationPreference struct {
+	Model
+	UserID uuid.UUID `gorm:"not null;uniqueIndex" json:"userId"`
+	// types without settings are delivered on every channel
+	Channels     NotificationChannels `gorm:"type:jsonb" json:"channels"`
+	DeliveryMode DeliveryMode         `gorm:"type:varchar(20);not null;default:'instant'" json:"deliveryMode"`
+	// how often digest emails are sent in digest mode
+	DigestFrequency DigestFrequency `gorm:"type:varchar(20);not null;default:'daily'" json:"digestFrequency"`
+	// quiet hours are disabled when empty. The window may wrap around midnight
+	QuietHoursStart string `gorm:"type:varchar(5)" json:"quietHoursStart"`
+	QuietHoursEnd   string `gorm:"type:varchar(5)" json:"quietHoursEnd"`
+	// IANA timezone of the quiet hours
+	Timezone string `gorm:"type:varchar(64);not null;default:'UTC'" json:"timezone"`
+	// set by the unsubscribe link, stops all notification emails
+	EmailUnsubscribed bool `gorm:"not null;default:false" json:"emailUnsubscribed"`
+	// when the last digest email was enqueued, so retried digest runs skip the user
+	LastDigestAt *time.Time `json:"-"`
+
+	// relations
+	User *User `json:"user,omitempty"`
+}
+
+// DefaultNotificationPreference returns the settings of users that never changed their preferences
+func DefaultNotificationPreference(userID uuid.UUID) *NotificationPreference {
+	return &NotificationPreference{
+		UserID:          userID,
+		Channels:        NotificationChannels{},
+		DeliveryMode:    InstantDelivery,
+		DigestFrequency: DailyDigest,
+		Timezone:        "UTC",
+	}
+}
+
+// Allows reports whether a notification type may be delivered on a channel. Transactional types are always allowed
+func (p *NotificationPreference) Allows(channel NotificationChannel, notificationType NotificationType) bool {
+	// digests are only sent by email and stop with the unsubscribe link
+	if notificationType == DigestNotification {
+		return channel == EmailChannel && !p.EmailUnsubscribed
+	}
+	if !notificationType.Configurable() {
+		return true
+	}
+
+	if channel == EmailChannel && p.EmailUnsubscribed {
+		return false
+	}
+
+	settings, ok := p.Channels[notificationType]
+	if !ok {
+		return true
+	}
+	switch channel {
+	case EmailChannel:
+		return settings.Email
+	case PushChannel:
+		return settings.Push
+	default:
+		return true
+	}
+}
+
+// QuietUntil returns the end of the quiet hours t falls in. ok is false when t is outside quiet hours or they are disabled
+func (p *NotificationPreference) QuietUntil(t time.Time) (end time.Time, ok bool) {
+	if p.QuietHoursStart == "" || p.QuietHoursEnd == "" {
+		return time.Time{}, false
+	}
+	start, err := time.Parse(QuietHoursLayout, p.QuietHoursStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+	stop, err := time.Parse(QuietHoursLayout, p.QuietHoursEnd)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+
+	// compare minutes since midnight
+	now := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := stop.Hour()*60 + stop.Minute()
+
+	var quiet bool
+	switch {
+	case from == to:
+		return time.Time{}, false
+	case from < to:
+		quiet = now >= from && now < to
+	default:
+		// e.g. 22:00 - 07:00
+		quiet = now >= from || now < to
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	end = time.Date(local.Year(), local.Month(), local.Day(), stop.Hour(), stop.Minute(), 0, 0, loc)
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end, true
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Channels          NotificationChannels `json:"channels"`
+	DeliveryMode      DeliveryMode         `json:"deliveryMode" binding:"required,oneof=instant digest"`
+	DigestFrequency   DigestFrequency      `json:"digestFrequency" binding:"omitempty,oneof=daily weekly"`
+	QuietHoursStart   string               `json:"quietHoursStart" binding:"required_with=QuietHoursEnd,omitempty,datetime=15:04"`
+	QuietHoursEnd     string               `json:"quietHoursEnd" binding:"required_with=QuietHoursStart,omitempty,datetime=15:04"`
+	Timezone          string               `json:"timezone" binding:"omitempty,timezone"`
+	EmailUnsubscribed bool                 `json:"emailUnsubscribed"`
+}
+
+type UnsubscribeQuery struct {
+	Token string `form:"token" binding:"required"`
+}
@@ -131,10 +131,23 @@ func (u *UpdateProfileRequest) Compose() (*Profile, error) {
 	return p, nil
 }
 
+// NearbySort orders the nearby profiles
+type NearbySort string
+
+const (
+	// nearest first, boosted profiles rank as if they were closer
+	SortByDistance NearbySort = "distance"
+	// most and rarest shared interests first
+	SortByInterests NearbySort = "interests"
+)
+
 type GetNearbyProfilesRequest struct {
 	Lat    float64 `form:"lat" binding:"required,latitude"`
 	Lng    float64 `form:"lng" binding:"required,longitude"`
 	Radius float64 `form:"radius,default=5000" binding:"min=100,max=50000"`
 	Limit  int     `form:"limit,default=20" binding:"min=1,max=100"`
 	Offset int     `form:"offset,default=0" binding:"min=0"`
+	// leave out profiles shown to the user during the last week
+	ExcludeSeen bool       `form:"excludeSeen"`
+	Sort        NearbySort `form:"sort,default=distance" binding:"oneof=distance interests"`
 }
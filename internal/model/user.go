@@ -26,18 +26,41 @@ type User struct {
 	Provider   string     `gorm:"not null" json:"provider"`
 	Role       UserRole   `gorm:"type:varchar(100);default:'user'" json:"role"`
 	LastActive *time.Time `json:"lastActive"`
+	// phone number in E.164 format
+	PhoneNumber   *string `gorm:"type:varchar(20);uniqueIndex" json:"phoneNumber"`
+	PhoneVerified bool    `gorm:"not null;default:false" json:"phoneVerified"`
+	// preferred language for notifications
+	Locale string `gorm:"type:varchar(10);not null;default:'en'" json:"locale"`
 
 	// relations
 	Profile *Profile `json:"profile,omitempty"`
 }
 
 type AuthenticatedUser struct {
-	ID       uuid.UUID `json:"id"`
-	Username string    `json:"username"`
-	Role     UserRole  `json:"role"`
+	ID            uuid.UUID `json:"id"`
+	Username      string    `json:"username"`
+	Role          UserRole  `json:"role"`
+	PhoneVerified bool      `json:"phoneVerified"`
 }
 
 type AuthResponse struct {
 	Token string `json:"token"`
 	User  User   `json:"user"`
 }
+
+type UpdatePhoneNumberRequest struct {
+	PhoneNumber string `json:"phoneNumber" binding:"required,min=7,max=20"`
+}
+
+// OTPCooldownDetail tells when a new verification code can be requested
+type OTPCooldownDetail struct {
+	RetryAfterSeconds int `json:"retryAfterSeconds"`
+}
+
+type VerifyPhoneNumberRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type UpdateLocaleRequest struct {
+	Locale string `json:"locale" binding:"required,max=10,bcp47_language_tag"`
+}
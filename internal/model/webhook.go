@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+type WebhookEventStatus string
+
+const (
+	WebhookReceived  WebhookEventStatus = "received"
+	WebhookProcessed WebhookEventStatus = "processed"
+	// events the app does not act on
+	WebhookIgnored WebhookEventStatus = "ignored"
+	// processing failed and is retried by the worker
+	WebhookFailed WebhookEventStatus = "failed"
+)
+
+// WebhookEvent is a raw webhook delivery. Providers retry deliveries, the event id makes sure each event is processed once
+type WebhookEvent struct {
+	Model
+	Provider string `gorm:"type:varchar(20);not null;uniqueIndex:idx_webhook_events_provider_event" json:"provider"`
+	EventID  string `gorm:"type:varchar(255);not null;uniqueIndex:idx_webhook_events_provider_event" json:"eventId"`
+	Event    string `gorm:"type:varchar(100);not null" json:"event"`
+	// raw body as received
+	Payload     string             `gorm:"type:jsonb;not null" json:"payload"`
+	Status      WebhookEventStatus `gorm:"type:varchar(20);not null;default:'received'" json:"status"`
+	Error       string             `gorm:"type:text" json:"error,omitempty"`
+	ProcessedAt *time.Time         `json:"processedAt"`
+}
@@ -1,12 +1,90 @@
 package model
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskMeta is embedded in task payloads to carry the request that enqueued the task, so the logs and traces of both can
+// be correlated
+type TaskMeta struct {
+	RequestID string `json:"request_id,omitempty"`
+	// w3c trace context of the span that enqueued the task
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+}
+
 type EmailPayload struct {
-	Email   string `json:"email"`
-	Message string `json:"message"`
-	Subject string `json:"subject"`
+	TaskMeta
+	Email string `json:"email"`
+	// recipient and notification type, empty for transactional emails that skip notification preferences
+	UserID uuid.UUID        `json:"user_id,omitempty"`
+	Type   NotificationType `json:"type,omitempty"`
+	// template id, usually the notification type
+	TemplateID string         `json:"template_id"`
+	Locale     string         `json:"locale"`
+	Data       map[string]any `json:"data,omitempty"`
 }
 
 type SMSPayload struct {
+	TaskMeta
 	PhoneNumbers []string `json:"phone_numbers"`
 	Message      string   `json:"message"`
 }
+
+type InAppPayload struct {
+	TaskMeta
+	UserID uuid.UUID         `json:"user_id"`
+	Type   NotificationType  `json:"type"`
+	Title  string            `json:"title"`
+	Body   string            `json:"body"`
+	Data   map[string]string `json:"data,omitempty"`
+}
+
+type PushPayload struct {
+	TaskMeta
+	UserID uuid.UUID         `json:"user_id"`
+	Type   NotificationType  `json:"type"`
+	Title  string            `json:"title"`
+	Body   string            `json:"body"`
+	Data   map[string]string `json:"data,omitempty"`
+}
+
+type DigestPayload struct {
+	Frequency DigestFrequency `json:"frequency"`
+}
+
+type SeedCachePayload struct {
+	// diff every active profile instead of only the ones updated since the last run
+	Reconcile bool `json:"reconcile"`
+}
+
+type ProfileSyncPayload struct {
+	TaskMeta
+	UserID uuid.UUID `json:"user_id"`
+	// time of the database write, used to measure sync lag
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// CacheSyncStats are the counters of the profile to interest cache synchronization
+type CacheSyncStats struct {
+	Synced        int64      `json:"synced"`
+	Failed        int64      `json:"failed"`
+	EnqueueFailed int64      `json:"enqueueFailed"`
+	LastLagMs     int64      `json:"lastLagMs"`
+	LastSyncedAt  *time.Time `json:"lastSyncedAt"`
+	LastFailedAt  *time.Time `json:"lastFailedAt"`
+	LastError     string     `json:"lastError"`
+}
+
+type WebhookEventPayload struct {
+	TaskMeta
+	EventID uuid.UUID `json:"event_id"`
+}
+
+type PaymentVerificationPayload struct {
+	TaskMeta
+	Reference string `json:"reference"`
+	// number of verifications done so far
+	Attempt int `json:"attempt"`
+}
@@ -0,0 +1,312 @@
+// Package paystacktest provides an in-memory stand-in for the paystack api, so the billing flow can run offline. Point
+// the paystack client at it with PAYSTACK_BASE_URL
+package paystacktest
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"konnect/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrUnknownReference = errors.New("unknown transaction reference")
+
+// OTP is the otp that completes charges waiting in the send_otp status
+const OTP = "123456"
+
+// Server is a fake paystack api. Transactions start pending and are completed with Complete or Fail, or by submitting
+// OTP for mobile money charges. MTN charges wait for an approval on the phone, other networks ask for an otp
+type Server struct {
+	*httptest.Server
+
+	secret string
+
+	mu           sync.Mutex
+	nextID       int64
+	transactions map[string]*model.PaystackTransaction
+	// responses left to fail with a server error
+	failures int
+	// requests left to handle without responding
+	drops int
+}
+
+// NewServer starts a fake paystack api accepting the secret key. It must be closed after use
+func NewServer(secret string) *Server {
+	s := &Server{
+		secret:       secret,
+		transactions: make(map[string]*model.PaystackTransaction),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /transaction/initialize", s.initializeTransaction)
+	mux.HandleFunc("GET /transaction/verify/{reference}", s.verifyTransaction)
+	mux.HandleFunc("POST /charge", s.charge)
+	mux.HandleFunc("POST /charge/submit_otp", s.submitOTP)
+	s.Server = httptest.NewServer(s.authenticate(mux))
+
+	return s
+}
+
+// BaseURL is the base url of the paystack client
+func (s *Server) BaseURL() string {
+	return s.URL + "/"
+}
+
+// FailNext makes the next n requests fail with a server error, e.g. to exercise retries
+func (s *Server) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+}
+
+// DropNext makes the next n requests be handled without a response reaching the client, like a response lost on the
+// network, e.g. to exercise charges whose outcome is unknown
+func (s *Server) DropNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drops = n
+}
+
+// Complete marks a transaction as paid. It returns false for unknown references
+func (s *Server) Complete(reference string) bool {
+	return s.setStatus(reference, model.PaystackSuccess, "Approved")
+}
+
+// Fail marks a transaction as declined. It returns false for unknown references
+func (s *Server) Fail(reference string) bool {
+	return s.setStatus(reference, model.PaystackFailed, "Declined")
+}
+
+// Transaction returns a copy of the transaction of a reference
+func (s *Server) Transaction(reference string) (model.PaystackTransaction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	transaction, ok := s.transactions[reference]
+	if !ok {
+		return model.PaystackTransaction{}, false
+	}
+	return *transaction, true
+}
+
+// Webhook builds the body and x-paystack-signature header of a webhook event for a transaction
+func (s *Server) Webhook(event, reference string) ([]byte, string, error) {
+	transaction, ok := s.Transaction(reference)
+	if !ok {
+		return nil, "", ErrUnknownReference
+	}
+	data, err := json.Marshal(transaction)
+	if err != nil {
+		return nil, "", err
+	}
+	payload, err := json.Marshal(model.PaystackEvent{Event: event, Data: data})
+	if err != nil {
+		return nil, "", err
+	}
+
+	mac := hmac.New(sha512.New, []byte(s.secret))
+	mac.Write(payload)
+	return payload, hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// authenticate checks the secret key and serves the failures set with FailNext and DropNext
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+s.secret {
+			writeError(w, http.StatusUnauthorized, "Invalid key")
+			return
+		}
+
+		s.mu.Lock()
+		fail := s.failures > 0
+		if fail {
+			s.failures--
+		}
+		drop := !fail && s.drops > 0
+		if drop {
+			s.drops--
+		}
+		s.mu.Unlock()
+		if fail {
+			writeError(w, http.StatusInternalServerError, "An error occurred")
+			return
+		}
+		if drop {
+			next.ServeHTTP(httptest.NewRecorder(), r)
+			if conn, _, err := http.NewResponseController(w).Hijack(); err == nil {
+				conn.Close()
+			}
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) initializeTransaction(w http.ResponseWriter, r *http.Request) {
+	var body model.PaystackInitTransfer
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Email == "" || body.Reference == nil {
+		writeError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	amount, err := strconv.ParseInt(body.Amount, 10, 64)
+	if err != nil || amount <= 0 {
+		writeError(w, http.StatusBadRequest, "Invalid amount")
+		return
+	}
+
+	transaction, ok := s.create(*body.Reference, amount, body.Currency, body.Email, "card")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Duplicate Transaction Reference")
+		return
+	}
+
+	var resp model.PaystackInitTransferResponse
+	resp.Status = true
+	resp.Message = "Authorization URL created"
+	resp.Data.Reference = transaction.Reference
+	resp.Data.AccessCode = "access_" + transaction.Reference
+	resp.Data.AuthorizationURL = s.URL + "/checkout/" + resp.Data.AccessCode
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) verifyTransaction(w http.ResponseWriter, r *http.Request) {
+	transaction, ok := s.Transaction(r.PathValue("reference"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Transaction reference not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, model.PaystackVerifyResponse{Status: true, Message: "Verification successful", Data: transaction})
+}
+
+func (s *Server) charge(w http.ResponseWriter, r *http.Request) {
+	var body model.PaystackCreateCharge
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Email == "" || body.Reference == nil || body.Amount <= 0 {
+		writeError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	if body.MobileMoney.Phone == "" || body.MobileMoney.Provider == "" {
+		writeError(w, http.StatusBadRequest, "Mobile money details are required")
+		return
+	}
+
+	status, text := model.PaystackSendOTP, "Please enter the otp sent to your phone"
+	if body.MobileMoney.Provider == model.MTN {
+		status, text = model.PaystackPayOffline, "Please complete authorization process on your mobile phone"
+	}
+	transaction, ok := s.create(*body.Reference, int64(body.Amount), body.Currency, body.Email, "mobile_money")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Duplicate Transaction Reference")
+		return
+	}
+	s.setStatus(transaction.Reference, status, "")
+
+	writeJSON(w, http.StatusOK, model.PaystackCreateChargeResponse{
+		Status:  true,
+		Message: "Charge attempted",
+		Data: model.PaystackCharge{
+			Reference:   transaction.Reference,
+			Status:      status,
+			DisplayText: text,
+			Amount:      transaction.Amount,
+			Currency:    transaction.Currency,
+		},
+	})
+}
+
+func (s *Server) submitOTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		OTP       string `json:"otp"`
+		Reference string `json:"reference"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	transaction, ok := s.Transaction(body.Reference)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Transaction reference not found")
+		return
+	}
+	if transaction.Status != model.PaystackSendOTP {
+		writeError(w, http.StatusBadRequest, "Charge is not awaiting an otp")
+		return
+	}
+	if body.OTP != OTP {
+		writeError(w, http.StatusBadRequest, "Invalid OTP")
+		return
+	}
+
+	s.Complete(body.Reference)
+	transaction, _ = s.Transaction(body.Reference)
+	writeJSON(w, http.StatusOK, model.PaystackSubmitOtpResponse{
+		Status:  true,
+		Message: "Charge attempted",
+		Data: model.PaystackCharge{
+			Reference:       transaction.Reference,
+			Status:          transaction.Status,
+			Amount:          transaction.Amount,
+			Currency:        transaction.Currency,
+			GatewayResponse: transaction.GatewayResponse,
+		},
+	})
+}
+
+// create records a pending transaction. References are unique like on paystack
+func (s *Server) create(reference string, amount int64, currency, email, channel string) (*model.PaystackTransaction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.transactions[reference]; ok {
+		return nil, false
+	}
+	if currency == "" {
+		currency = "GHS"
+	}
+
+	s.nextID++
+	transaction := &model.PaystackTransaction{
+		ID:        s.nextID,
+		Status:    model.PaystackOngoing,
+		Reference: reference,
+		Amount:    amount,
+		Currency:  currency,
+		Channel:   channel,
+		Customer:  model.PaystackCustomer{Email: email},
+	}
+	s.transactions[reference] = transaction
+	return transaction, true
+}
+
+func (s *Server) setStatus(reference string, status model.PaystackStatus, gatewayResponse string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	transaction, ok := s.transactions[reference]
+	if !ok {
+		return false
+	}
+
+	transaction.Status = status
+	transaction.GatewayResponse = gatewayResponse
+	if status == model.PaystackSuccess {
+		paidAt := time.Now()
+		transaction.PaidAt = &paidAt
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, model.PaystackErrorResponse{Status: false, Message: message})
+}
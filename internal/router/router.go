@@ -8,20 +8,41 @@ import (
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func RegisterRoutes(router *gin.Engine, middleware *handler.Middleware, authHandler *handler.AuthHandler, profileHandler *handler.ProfileHandler, swipeHandler *handler.SwipeHandler) {
+// routes left out of traces, they are hit every few seconds by orchestrators
+var untracedRoutes = map[string]bool{
+	"/api/health":       true,
+	"/api/health/live":  true,
+	"/api/health/ready": true,
+}
+
+func RegisterRoutes(router *gin.Engine, middleware *handler.Middleware, authHandler *handler.AuthHandler, profileHandler *handler.ProfileHandler, swipeHandler *handler.SwipeHandler, notificationHandler *handler.NotificationHandler, deviceHandler *handler.DeviceHandler, userHandler *handler.UserHandler, emailTemplateHandler *handler.EmailTemplateHandler, adminHandler *handler.AdminHandler, interestHandler *handler.InterestHandler, billingHandler *handler.BillingHandler, webhookHandler *handler.WebhookHandler, healthHandler *handler.HealthHandler) {
+	// request ids first so every access log and panic carries one. Requests are traced unless they are probes
+	router.Use(
+		middleware.RequestID(),
+		otelgin.Middleware("konnect-api", otelgin.WithGinFilter(func(c *gin.Context) bool { return !untracedRoutes[c.FullPath()] })),
+		middleware.AccessLog(),
+		middleware.Metrics(),
+		middleware.Recovery(),
+	)
+
 	// cors
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Authorization", "X-Forwarded-For", "Origin", "Content-Type", "Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Authorization", "X-Forwarded-For", "Origin", "Content-Type", "Content-Length", handler.RequestIDHeader},
+		ExposeHeaders:    []string{handler.RequestIDHeader},
 		AllowCredentials: true,
 	}))
 
 	// base api router
 	apiRouter := router.Group("/api")
-	apiRouter.GET("/health", func(ctx *gin.Context) { ctx.JSON(200, "OK") })
+	// probes. /health is kept for existing checks
+	apiRouter.GET("/health", healthHandler.Live)
+	apiRouter.GET("/health/live", healthHandler.Live)
+	apiRouter.GET("/health/ready", healthHandler.Ready)
 
 	// swagger
 	docs.SwaggerInfo.BasePath = "/api"
@@ -34,16 +55,37 @@ func RegisterRoutes(router *gin.Engine, middleware *handler.Middleware, authHand
 		auth.GET("/google/callback", authHandler.GoogleCallback)
 	}
 
+	// interest catalog for onboarding
+	apiRouter.GET("/interests", interestHandler.GetInterests)
+
+	// unsubscribe links in emails are signed and do not need a session. The link opens a confirmation page and only
+	// POST unsubscribes, which is also what mail clients supporting rfc 8058 one-click unsubscribe send
+	apiRouter.GET("/notifications/unsubscribe", notificationHandler.UnsubscribePage)
+	apiRouter.POST("/notifications/unsubscribe", notificationHandler.Unsubscribe)
+
+	// payment provider webhooks are verified by their signature
+	apiRouter.POST("/webhooks/paystack", webhookHandler.PaystackWebhook)
+
 	// protected routes
 	protected := apiRouter.Group("")
 	protected.Use(middleware.AuthMiddleware())
 	{
+		// users
+		users := protected.Group("/users")
+		{
+			users.PUT("/me/phone", userHandler.UpdatePhoneNumber)
+			users.POST("/me/phone/resend", userHandler.ResendPhoneVerificationCode)
+			users.POST("/me/phone/verify", userHandler.VerifyPhoneNumber)
+			users.PUT("/me/locale", userHandler.UpdateLocale)
+		}
+
 		// profiles
 		profiles := protected.Group("/profiles")
 		{
 			profiles.POST("", profileHandler.CreateProfile)
 			profiles.PATCH("", profileHandler.UpdateProfile)
 			profiles.GET("/me", profileHandler.GetCurrentUserProfile)
+			profiles.GET("/me/views", profileHandler.GetProfileViews)
 			profiles.GET("/nearby", profileHandler.GetNearbyProfiles)
 			profiles.POST("/photo", profileHandler.UploadProfilePhoto)
 			profiles.GET("/:id", profileHandler.GetProfile)
@@ -54,6 +96,57 @@ func RegisterRoutes(router *gin.Engine, middleware *handler.Middleware, authHand
 		{
 			swipes.POST("", swipeHandler.CreateSwipe)
 			swipes.GET("/me", swipeHandler.GetUserSwipeHistory)
+			swipes.GET("/likes", swipeHandler.GetLikesReceived)
+			swipes.POST("/rewind", swipeHandler.RewindSwipe)
+		}
+
+		// premium subscriptions
+		billing := protected.Group("/billing")
+		{
+			billing.GET("/plans", billingHandler.GetPlans)
+			billing.POST("/checkout", billingHandler.Checkout)
+			billing.POST("/mobile-money", billingHandler.ChargeMobileMoney)
+			billing.POST("/mobile-money/:reference/otp", billingHandler.SubmitMobileMoneyOTP)
+			billing.GET("/payments/:reference", billingHandler.VerifyPayment)
+			billing.GET("/subscription", billingHandler.GetSubscription)
+			billing.GET("/boosts", billingHandler.GetBoosts)
+		}
+
+		// interest analytics
+		interests := protected.Group("/interests")
+		{
+			interests.GET("/stats", interestHandler.GetInterestStats)
+			interests.GET("/trending", interestHandler.GetTrendingInterests)
+		}
+
+		// notifications
+		notifications := protected.Group("/notifications")
+		{
+			notifications.GET("", notificationHandler.GetNotifications)
+			notifications.PATCH("/read", notificationHandler.MarkAllAsRead)
+			notifications.PATCH("/:id/read", notificationHandler.MarkAsRead)
+			notifications.GET("/preferences", notificationHandler.GetPreferences)
+			notifications.PUT("/preferences", notificationHandler.UpdatePreferences)
+		}
+
+		// push devices
+		devices := protected.Group("/devices")
+		{
+			devices.POST("", deviceHandler.RegisterDevice)
+			devices.DELETE("", deviceHandler.UnregisterDevice)
+		}
+
+		// admin
+		admin := protected.Group("/admin")
+		{
+			admin.GET("/email-templates", emailTemplateHandler.GetTemplates)
+			admin.POST("/email-templates/:id/preview", emailTemplateHandler.PreviewTemplate)
+			admin.GET("/cache/sync-stats", adminHandler.GetCacheSyncStats)
+			admin.GET("/interests", interestHandler.GetAllInterests)
+			admin.POST("/interests", interestHandler.CreateInterest)
+			admin.PATCH("/interests/:id", interestHandler.UpdateInterest)
+			admin.DELETE("/interests/:id", interestHandler.RetireInterest)
+			admin.POST("/interests/:id/merge", interestHandler.MergeInterest)
 		}
 	}
 }
@@ -11,18 +11,97 @@ import (
 	"go.uber.org/zap"
 )
 
-func SeedInterestCache(db *database.DB, interestCache *cache.InterestCache, logger *logger.Logger) error {
-	logger.Info("Starting cache seeding process...")
+// profiles active within this many days are kept in the interest buckets
+const activeProfileDays = 30
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+// SeedInterestCache syncs profiles updated since the last run into the interest cache. A full reconciliation
+// diffs every active profile against the cache instead and evicts stale bucket members
+func SeedInterestCache(db *database.DB, interestCache *cache.InterestCache, reconcile bool, logger *logger.Logger) error {
+	logger.Info("Starting cache seeding process...", zap.Bool("reconcile", reconcile))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
+	interests, err := catalogInterests(ctx, db)
+	if err != nil {
+		logger.Warn("Failed to load interest catalog", zap.Error(err))
+		return err
+	}
+
 	// seed interests for users active in the 30 days
-	if err := interestCache.SeedActiveProfiles(ctx, db, model.SystemInterests, 30); err != nil {
+	var result *cache.SeedResult
+	if reconcile {
+		result, err = interestCache.ReconcileActiveProfiles(ctx, db, interests, activeProfileDays)
+	} else {
+		result, err = interestCache.SeedActiveProfiles(ctx, db, interests, activeProfileDays)
+	}
+	if err != nil {
 		logger.Warn("Failed to seed active user interests", zap.Error(err))
 		return err
 	}
 
-	logger.Info("Cache seeding process complete")
+	logger.Info("Cache seeding process complete",
+		zap.Int("profiles", result.Profiles),
+		zap.Int("added", result.Added),
+		zap.Int("removed", result.Removed),
+	)
+	return nil
+}
+
+// SeedGeoIndex writes every profile location to the redis location index and evicts deleted profiles
+func SeedGeoIndex(db *database.DB, geoCache *cache.GeoCache, logger *logger.Logger) error {
+	logger.Info("Starting geo index seeding process...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if _, err := geoCache.SeedLocations(ctx, db); err != nil {
+		logger.Warn("Failed to seed profile locations", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Geo index seeding process complete")
+	return nil
+}
+
+// PruneInterestCache drops users that are no longer active from the interest buckets
+func PruneInterestCache(db *database.DB, interestCache *cache.InterestCache, logger *logger.Logger) error {
+	logger.Info("Starting interest cache pruning process...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	interests, err := catalogInterests(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	if _, err := interestCache.PruneInactiveProfiles(ctx, db, interests, activeProfileDays); err != nil {
+		logger.Warn("Failed to prune inactive user interests", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Interest cache pruning process complete")
 	return nil
 }
+
+// ExpireStaleFeeds makes sure every cached feed eventually expires
+func ExpireStaleFeeds(feedCache *cache.FeedCache, logger *logger.Logger) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	if _, err := feedCache.ExpireStaleFeeds(ctx, cache.FeedTTL); err != nil {
+		logger.Warn("Failed to expire stale feeds", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// catalogInterests returns every interest of the catalog. Retired interests are included since profiles keep them
+func catalogInterests(ctx context.Context, db *database.DB) ([]string, error) {
+	var interests []string
+	if err := db.WithContext(ctx).Model(&model.Interest{}).Pluck("name", &interests).Error; err != nil {
+		return nil, err
+	}
+	return interests, nil
+}
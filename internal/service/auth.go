@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"konnect/internal/config"
 	"konnect/internal/database"
@@ -22,6 +23,7 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 	ErrInvalidToken = errors.New("invalid token")
 	ErrUserNotFound = errors.New("user not found")
+	ErrPhoneTaken   = errors.New("phone number is already in use")
 )
 
 type AuthService struct {
@@ -39,7 +41,7 @@ func NewAuthService(db *database.DB, cfg *config.Config, logger *logger.Logger)
 }
 
 // UpsertUserFromProvider finds a user by their email or creates a new one
-func (s *AuthService) UpsertUserFromProvider(gothUser goth.User) (*model.User, error) {
+func (s *AuthService) UpsertUserFromProvider(ctx context.Context, gothUser goth.User) (*model.User, error) {
 	now := time.Now()
 	username := util.GenerateRandomUsername()
 
@@ -52,11 +54,11 @@ func (s *AuthService) UpsertUserFromProvider(gothUser goth.User) (*model.User, e
 	}
 
 	// upsert user by email
-	if err := s.db.Clauses(clause.OnConflict{
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "email"}},
 		DoUpdates: clause.AssignmentColumns([]string{"last_active"}),
 	}).Create(&user).Error; err != nil {
-		s.logError(err, "failed to upsert user", zap.String("email", gothUser.Email), zap.String("provider", gothUser.Provider))
+		s.logError(ctx, err, "failed to upsert user", zap.String("email", gothUser.Email), zap.String("provider", gothUser.Provider))
 		return nil, err
 	}
 
@@ -64,9 +66,9 @@ func (s *AuthService) UpsertUserFromProvider(gothUser goth.User) (*model.User, e
 }
 
 // GetUserByUsername retrieves user by ID
-func (s *AuthService) GetUserByUsername(username string) (*model.User, error) {
+func (s *AuthService) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
-	if err := s.db.First(&user, "username = ?", username).Error; err != nil {
+	if err := s.db.WithContext(ctx).First(&user, "username = ?", username).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrUserNotFound
 		}
@@ -76,9 +78,9 @@ func (s *AuthService) GetUserByUsername(username string) (*model.User, error) {
 }
 
 // GetUserByID retrieves user by ID
-func (s *AuthService) GetUserByID(id uuid.UUID) (*model.User, error) {
+func (s *AuthService) GetUserByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
 	var user model.User
-	if err := s.db.Where("id = ?", id).Joins("Profile").Take(&user).Error; err != nil {
+	if err := s.db.WithContext(ctx).Where("id = ?", id).Joins("Profile").Take(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrUserNotFound
 		}
@@ -88,14 +90,73 @@ func (s *AuthService) GetUserByID(id uuid.UUID) (*model.User, error) {
 }
 
 // UpdateLastActive updates user's last active timestamp
-func (s *AuthService) UpdateLastActive(userID string) error {
+func (s *AuthService) UpdateLastActive(ctx context.Context, userID string) error {
 	now := time.Now()
-	return s.db.Model(&model.User{}).Where("id = ?", userID).Update("last_active", now).Error
+	return s.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("last_active", now).Error
+}
+
+// UpdatePhoneNumber normalizes the phone number to E.164 and assigns it to the user. Changing the number resets its verification
+func (s *AuthService) UpdatePhoneNumber(ctx context.Context, userID uuid.UUID, phone string) (*model.User, error) {
+	phoneNumber, err := util.NormalizePhoneNumber(phone, s.cfg.DefaultCountryCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// phone numbers are unique across users
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&model.User{}).Where("phone_number = ? AND id != ?", phoneNumber, userID).Count(&count).Error; err != nil {
+		s.logError(ctx, err, "failed to check phone number availability", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrPhoneTaken
+	}
+
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// keep the verification status when the number is unchanged
+	if user.PhoneNumber != nil && *user.PhoneNumber == phoneNumber {
+		return user, nil
+	}
+
+	// a new number must be verified again
+	res := s.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Updates(map[string]any{
+		"phone_number":   phoneNumber,
+		"phone_verified": false,
+	})
+	if res.Error != nil {
+		s.logError(ctx, res.Error, "failed to update phone number", zap.String("user_id", userID.String()))
+		if errors.Is(res.Error, gorm.ErrDuplicatedKey) {
+			return nil, ErrPhoneTaken
+		}
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	return s.GetUserByID(ctx, userID)
+}
+
+// UpdateLocale sets the preferred notification language of the user
+func (s *AuthService) UpdateLocale(ctx context.Context, userID uuid.UUID, locale string) (*model.User, error) {
+	res := s.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("locale", locale)
+	if res.Error != nil {
+		s.logError(ctx, res.Error, "failed to update locale", zap.String("user_id", userID.String()))
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	return s.GetUserByID(ctx, userID)
 }
 
 // token helpers (generate and validate)
 
-func (s *AuthService) GenerateAccessToken(user *model.User) (string, error) {
+func (s *AuthService) GenerateAccessToken(ctx context.Context, user *model.User) (string, error) {
 	now := time.Now()
 	expiry := now.Add(time.Duration(s.cfg.JWTExpiryMinutes) * time.Minute)
 	isVerified := false
@@ -105,19 +166,20 @@ func (s *AuthService) GenerateAccessToken(user *model.User) (string, error) {
 
 	// create token with claims
 	claims := jwt.MapClaims{
-		"sub":        user.ID.String(),
-		"username":   user.Username,
-		"role":       user.Role,
-		"isVerified": isVerified,
-		"iat":        now.Unix(),
-		"exp":        expiry.Unix(),
+		"sub":           user.ID.String(),
+		"username":      user.Username,
+		"role":          user.Role,
+		"isVerified":    isVerified,
+		"phoneVerified": user.PhoneVerified,
+		"iat":           now.Unix(),
+		"exp":           expiry.Unix(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// sign token with secret key
 	tokenString, err := token.SignedString([]byte(s.cfg.JWTSecret))
 	if err != nil {
-		s.logError(err, "failed to generate token", zap.String("user_id", user.ID.String()))
+		s.logError(ctx, err, "failed to generate token", zap.String("user_id", user.ID.String()))
 		return "", err
 	}
 
@@ -151,10 +213,10 @@ func (s *AuthService) ValidateToken(tokenString string) (jwt.MapClaims, error) {
 }
 
 // logger helpers
-func (s *AuthService) logError(err error, msg string, fields ...zap.Field) {
-	s.logger.Error(msg, append(fields, zap.Error(err))...)
+func (s *AuthService) logError(ctx context.Context, err error, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Error(msg, append(fields, zap.Error(err))...)
 }
 
-func (s *AuthService) logInfo(msg string, fields ...zap.Field) {
-	s.logger.Info(msg, fields...)
+func (s *AuthService) logInfo(ctx context.Context, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Info(msg, fields...)
 }
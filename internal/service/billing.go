@@ -0,0 +1,536 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"konnect/internal/config"
+	"konnect/internal/database"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/worker"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPlanNotFound    = errors.New("plan not found")
+	ErrPaymentNotFound = errors.New("payment not found")
+	// subscription webhooks may arrive before the charge that creates the subscription
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrPremiumRequired      = errors.New("premium subscription required")
+	ErrPaymentNotPending    = errors.New("payment is no longer pending")
+	// mobile money is charged to the verified phone number of the user
+	ErrPhoneNotVerified = errors.New("phone number has not been verified")
+)
+
+type BillingService struct {
+	db       *database.DB
+	worker   *asynq.Client
+	paystack *PaystackService
+	boosts   *BoostService
+	cfg      *config.Config
+	logger   *zap.Logger
+}
+
+func NewBillingService(db *database.DB, worker *asynq.Client, paystack *PaystackService, boosts *BoostService, cfg *config.Config, logger *logger.Logger) *BillingService {
+	return &BillingService{
+		db:       db,
+		worker:   worker,
+		paystack: paystack,
+		boosts:   boosts,
+		cfg:      cfg,
+		logger:   logger.With(zap.String("component", "billing_service")),
+	}
+}
+
+// GetPlans returns the plans users can buy, cheapest first
+func (s *BillingService) GetPlans(ctx context.Context) ([]model.Plan, error) {
+	var plans []model.Plan
+	if err := s.db.WithContext(ctx).Where("active = ?", true).Order("amount").Find(&plans).Error; err != nil {
+		s.logError(ctx, err, "failed to get plans")
+		return nil, err
+	}
+	return plans, nil
+}
+
+// Checkout records a pending payment for a plan and initializes its paystack transaction. The user completes the payment
+// on the returned checkout page
+func (s *BillingService) Checkout(ctx context.Context, userID uuid.UUID, planCode string) (*model.CheckoutResponse, error) {
+	plan, err := s.getPlan(ctx, planCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var user model.User
+	if err := s.db.WithContext(ctx).Select("id", "email").Where("id = ?", userID).Take(&user).Error; err != nil {
+		s.logError(ctx, err, "failed to get user for checkout", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+
+	payment := &model.Payment{
+		UserID:    userID,
+		PlanID:    plan.ID,
+		Reference: s.paystack.GenerateReference(),
+		Amount:    plan.Amount,
+		Currency:  plan.Currency,
+		Channel:   model.CheckoutChannel,
+		Status:    model.PaymentPending,
+	}
+	if err := s.db.WithContext(ctx).Create(payment).Error; err != nil {
+		s.logError(ctx, err, "failed to create payment", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+
+	var paystackPlan string
+	if plan.PaystackPlanCode != nil {
+		paystackPlan = *plan.PaystackPlanCode
+	}
+	resp, err := s.paystack.InitiateTransaction(payment.Reference, payment.Amount, payment.Currency, user.Email, paystackPlan, map[string]any{
+		"user_id": userID.String(),
+		"plan":    plan.Code,
+	})
+	if err != nil {
+		s.logError(ctx, err, "failed to initiate transaction", zap.String("reference", payment.Reference))
+		s.handleStartError(ctx, payment, err)
+		return nil, err
+	}
+
+	return &model.CheckoutResponse{
+		Reference:        payment.Reference,
+		AuthorizationURL: resp.Data.AuthorizationURL,
+		AccessCode:       resp.Data.AccessCode,
+	}, nil
+}
+
+// VerifyPayment checks a pending payment of the user with paystack and applies the result. Final payments are returned as is
+func (s *BillingService) VerifyPayment(ctx context.Context, userID uuid.UUID, reference string) (*model.Payment, error) {
+	var payment model.Payment
+	if err := s.db.WithContext(ctx).Where("reference = ? AND user_id = ?", reference, userID).Take(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	if payment.Status != model.PaymentPending {
+		return &payment, nil
+	}
+	return s.verifyTransaction(ctx, reference)
+}
+
+// ChargeMobileMoney charges a plan to the verified phone number of the user on its mobile money network. Most charges
+// wait for an otp or an approval on the phone, so the payment is verified periodically until its status is final
+func (s *BillingService) ChargeMobileMoney(ctx context.Context, userID uuid.UUID, planCode string) (*model.MobileMoneyChargeResponse, error) {
+	plan, err := s.getPlan(ctx, planCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var user model.User
+	if err := s.db.WithContext(ctx).Select("id", "email", "phone_number", "phone_verified").Where("id = ?", userID).Take(&user).Error; err != nil {
+		s.logError(ctx, err, "failed to get user for mobile money charge", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+	if user.PhoneNumber == nil || !user.PhoneVerified {
+		return nil, ErrPhoneNotVerified
+	}
+	provider, err := s.paystack.GetProvider(*user.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &model.Payment{
+		UserID:    userID,
+		PlanID:    plan.ID,
+		Reference: s.paystack.GenerateReference(),
+		Amount:    plan.Amount,
+		Currency:  plan.Currency,
+		Channel:   model.MobileMoneyChannel,
+		Status:    model.PaymentPending,
+	}
+	if err := s.db.WithContext(ctx).Create(payment).Error; err != nil {
+		s.logError(ctx, err, "failed to create payment", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+
+	resp, err := s.paystack.InitiateMobileMoneyCharge(payment.Reference, payment.Amount, payment.Currency, user.Email, s.paystack.LocalPhoneNumber(*user.PhoneNumber), provider)
+	if err != nil {
+		s.logError(ctx, err, "failed to initiate mobile money charge", zap.String("reference", payment.Reference))
+		if !s.handleStartError(ctx, payment, err) {
+			return nil, err
+		}
+		// the user checks the payment with its reference once it is verified
+		return &model.MobileMoneyChargeResponse{
+			Reference:   payment.Reference,
+			Provider:    provider,
+			Status:      model.PaystackPending,
+			DisplayText: "Your payment is being confirmed",
+		}, nil
+	}
+
+	if !s.applyCharge(ctx, &resp.Data, payment.Reference) {
+		s.scheduleVerification(ctx, payment.Reference)
+	}
+
+	return &model.MobileMoneyChargeResponse{
+		Reference:   payment.Reference,
+		Provider:    provider,
+		Status:      resp.Data.Status,
+		DisplayText: resp.Data.DisplayText,
+	}, nil
+}
+
+// SubmitMobileMoneyOTP completes a pending mobile money charge of the user with the otp sent to their phone
+func (s *BillingService) SubmitMobileMoneyOTP(ctx context.Context, userID uuid.UUID, reference, otp string) (*model.MobileMoneyChargeResponse, error) {
+	var payment model.Payment
+	err := s.db.WithContext(ctx).Where("reference = ? AND user_id = ? AND channel = ?", reference, userID, model.MobileMoneyChannel).Take(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		s.logError(ctx, err, "failed to get payment", zap.String("reference", reference))
+		return nil, err
+	}
+	if payment.Status != model.PaymentPending {
+		return nil, ErrPaymentNotPending
+	}
+
+	resp, err := s.paystack.SubmitOTP(reference, otp)
+	if err != nil {
+		s.logError(ctx, err, "failed to submit otp", zap.String("reference", reference))
+		return nil, err
+	}
+	// verifications scheduled by the charge pick up charges still pending
+	s.applyCharge(ctx, &resp.Data, reference)
+
+	return &model.MobileMoneyChargeResponse{
+		Reference:   reference,
+		Status:      resp.Data.Status,
+		DisplayText: resp.Data.DisplayText,
+	}, nil
+}
+
+// VerifyPendingPayment checks a pending payment with paystack and reports whether its status is final. It implements
+// the worker.PaymentVerifier interface
+func (s *BillingService) VerifyPendingPayment(ctx context.Context, reference string) (bool, error) {
+	var payment model.Payment
+	if err := s.db.WithContext(ctx).Select("status").Where("reference = ?", reference).Take(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+	if payment.Status != model.PaymentPending {
+		return true, nil
+	}
+
+	verified, err := s.verifyTransaction(ctx, reference)
+	if err != nil {
+		// paystack may not know the reference yet, e.g. when the charge was sent but not confirmed. Later verifications
+		// or the charge webhook settle it
+		if errors.Is(err, ErrPaystackClient) {
+			return false, nil
+		}
+		return false, err
+	}
+	return verified.Status != model.PaymentPending, nil
+}
+
+// applyCharge verifies a charge that paystack reports as final and reports whether its payment was updated. Pending
+// charges are left to the scheduled verifications
+func (s *BillingService) applyCharge(ctx context.Context, charge *model.PaystackCharge, reference string) bool {
+	if charge.Status != model.PaystackSuccess && charge.Status != model.PaystackFailed {
+		return false
+	}
+	payment, err := s.verifyTransaction(ctx, reference)
+	return err == nil && payment.Status != model.PaymentPending
+}
+
+// verifyTransaction fetches the paystack transaction of a reference and applies it to its payment
+func (s *BillingService) verifyTransaction(ctx context.Context, reference string) (*model.Payment, error) {
+	resp, err := s.paystack.VerifyTransaction(reference)
+	if err != nil {
+		s.logError(ctx, err, "failed to verify transaction", zap.String("reference", reference))
+		return nil, err
+	}
+	return s.ApplyTransaction(ctx, &resp.Data)
+}
+
+// ApplyTransaction updates the payment of a paystack transaction and grants the plan once it succeeds.
+// Payments are locked while applied and only pending payments change, so the same transaction may be applied repeatedly.
+// Renewals charged by paystack have no payment yet and get one recorded
+func (s *BillingService) ApplyTransaction(ctx context.Context, transaction *model.PaystackTransaction) (*model.Payment, error) {
+	var (
+		payment model.Payment
+		boost   *model.Boost
+	)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("reference = ?", transaction.Reference).Take(&payment).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = s.recordRenewal(tx, transaction, &payment)
+		}
+		if err != nil {
+			return err
+		}
+		if payment.Status != model.PaymentPending {
+			return nil
+		}
+
+		updates := map[string]any{"gateway_response": transaction.GatewayResponse}
+		switch transaction.Status {
+		case model.PaystackSuccess:
+			// references are known to clients, make sure the plan price was paid in full
+			if transaction.Amount < payment.Amount || transaction.Currency != payment.Currency {
+				logger.FromContext(ctx, s.logger).Error("transaction does not match payment",
+					zap.String("reference", payment.Reference),
+					zap.Int64("expected", payment.Amount),
+					zap.Int64("paid", transaction.Amount),
+					zap.String("currency", transaction.Currency),
+				)
+				updates["status"] = model.PaymentFailed
+				updates["gateway_response"] = "amount mismatch"
+				break
+			}
+
+			paidAt := time.Now()
+			if transaction.PaidAt != nil {
+				paidAt = *transaction.PaidAt
+			}
+			updates["status"] = model.PaymentSuccess
+			updates["paid_at"] = paidAt
+			var err error
+			if boost, err = s.fulfil(tx, &payment); err != nil {
+				return err
+			}
+		case model.PaystackFailed:
+			updates["status"] = model.PaymentFailed
+		case model.PaystackAbandoned:
+			updates["status"] = model.PaymentAbandoned
+		case model.PaystackReversed:
+			updates["status"] = model.PaymentReversed
+		default:
+			// still in progress
+		}
+
+		return tx.Model(&payment).Updates(updates).Error
+	})
+	if err != nil {
+		if !errors.Is(err, ErrPaymentNotFound) {
+			s.logError(ctx, err, "failed to apply transaction", zap.String("reference", transaction.Reference))
+		}
+		return nil, err
+	}
+	if boost != nil {
+		s.boosts.activate(ctx, boost)
+	}
+	return &payment, nil
+}
+
+// SetAutoRenew records whether paystack renews a user's subscription, following paystack subscription events
+func (s *BillingService) SetAutoRenew(ctx context.Context, subscription *model.PaystackSubscription, autoRenew bool) error {
+	var userID uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&model.User{}).Where("email = ?", subscription.Customer.Email).Pluck("id", &userID).Error; err != nil {
+		return err
+	}
+
+	updates := map[string]any{"auto_renew": autoRenew}
+	if subscription.SubscriptionCode != "" {
+		updates["paystack_subscription_code"] = subscription.SubscriptionCode
+	}
+	res := s.db.WithContext(ctx).Model(&model.Subscription{}).Where("user_id = ?", userID).Updates(updates)
+	if res.Error != nil {
+		s.logError(ctx, res.Error, "failed to update subscription renewal", zap.String("subscription_code", subscription.SubscriptionCode))
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// GetSubscription returns the subscription of a user, nil if they never subscribed, and their entitlements
+func (s *BillingService) GetSubscription(ctx context.Context, userID uuid.UUID) (*model.SubscriptionResponse, error) {
+	var subscription model.Subscription
+	err := s.db.WithContext(ctx).Preload("Plan").Where("user_id = ?", userID).Take(&subscription).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logError(ctx, err, "failed to get subscription", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+
+	response := &model.SubscriptionResponse{}
+	if err == nil {
+		response.Subscription = &subscription
+	}
+	response.Entitlements = s.entitlements(response.Subscription)
+	return response, nil
+}
+
+// GetEntitlements returns the features available to a user
+func (s *BillingService) GetEntitlements(ctx context.Context, userID uuid.UUID) (*model.Entitlements, error) {
+	var subscription model.Subscription
+	err := s.db.WithContext(ctx).Where("user_id = ? AND expires_at > ?", userID, time.Now()).Take(&subscription).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			entitlements := s.entitlements(nil)
+			return &entitlements, nil
+		}
+		s.logError(ctx, err, "failed to get entitlements", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+
+	entitlements := s.entitlements(&subscription)
+	return &entitlements, nil
+}
+
+// RequireFeature returns ErrPremiumRequired when the feature is not available to the user
+func (s *BillingService) RequireFeature(ctx context.Context, userID uuid.UUID, feature model.Feature) error {
+	entitlements, err := s.GetEntitlements(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(entitlements.Features, feature) {
+		return ErrPremiumRequired
+	}
+	return nil
+}
+
+// entitlements derives the features of a subscription. Free users get a daily like allowance
+func (s *BillingService) entitlements(subscription *model.Subscription) model.Entitlements {
+	if subscription == nil || !subscription.Active(time.Now()) {
+		return model.Entitlements{Features: []model.Feature{}, DailyLikeLimit: s.cfg.FreeDailyLikes}
+	}
+	return model.Entitlements{
+		Premium:   true,
+		Features:  model.PremiumFeatures,
+		ExpiresAt: &subscription.ExpiresAt,
+	}
+}
+
+// fulfil grants the plan of a successful payment. The boost it schedules is returned for boost plans
+func (s *BillingService) fulfil(tx *gorm.DB, payment *model.Payment) (*model.Boost, error) {
+	var plan model.Plan
+	if err := tx.Unscoped().Where("id = ?", payment.PlanID).Take(&plan).Error; err != nil {
+		return nil, err
+	}
+	if plan.Kind == model.BoostPlan {
+		return s.boosts.createBoost(tx, payment, &plan)
+	}
+	return nil, s.extendSubscription(tx, payment, &plan)
+}
+
+// extendSubscription adds the plan duration of a payment to the user's subscription. Renewals before expiry stack
+func (s *BillingService) extendSubscription(tx *gorm.DB, payment *model.Payment, plan *model.Plan) error {
+	duration := time.Duration(plan.DurationDays) * 24 * time.Hour
+	now := time.Now()
+
+	var subscription model.Subscription
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", payment.UserID).Take(&subscription).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return tx.Create(&model.Subscription{
+			UserID:             payment.UserID,
+			PlanID:             plan.ID,
+			CurrentPeriodStart: now,
+			ExpiresAt:          now.Add(duration),
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]any{"plan_id": plan.ID}
+	if subscription.Active(now) {
+		updates["expires_at"] = subscription.ExpiresAt.Add(duration)
+	} else {
+		updates["current_period_start"] = now
+		updates["expires_at"] = now.Add(duration)
+	}
+	return tx.Model(&subscription).Updates(updates).Error
+}
+
+// recordRenewal creates the pending payment of a recurring charge paystack made on its own. Transactions that are not
+// renewals of a known plan and customer return ErrPaymentNotFound
+func (s *BillingService) recordRenewal(tx *gorm.DB, transaction *model.PaystackTransaction, payment *model.Payment) error {
+	planCode := transaction.PlanCode()
+	if planCode == "" || transaction.Customer.Email == "" {
+		return ErrPaymentNotFound
+	}
+
+	var plan model.Plan
+	if err := tx.Unscoped().Where("paystack_plan_code = ?", planCode).Take(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPaymentNotFound
+		}
+		return err
+	}
+	var user model.User
+	if err := tx.Select("id").Where("email = ?", transaction.Customer.Email).Take(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPaymentNotFound
+		}
+		return err
+	}
+
+	*payment = model.Payment{
+		UserID:    user.ID,
+		PlanID:    plan.ID,
+		Reference: transaction.Reference,
+		Amount:    plan.Amount,
+		Currency:  plan.Currency,
+		Channel:   model.RenewalChannel,
+		Status:    model.PaymentPending,
+	}
+	return tx.Create(payment).Error
+}
+
+func (s *BillingService) getPlan(ctx context.Context, code string) (*model.Plan, error) {
+	var plan model.Plan
+	if err := s.db.WithContext(ctx).Where("code = ? AND active = ?", code, true).Take(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPlanNotFound
+		}
+		s.logError(ctx, err, "failed to get plan", zap.String("code", code))
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// handleStartError settles a payment whose paystack transaction or charge could not be confirmed. Payments paystack
+// rejected are failed. Others may have been started, e.g. when the response was lost or the reference exists already,
+// so they stay pending and are verified later. It reports whether the payment stays pending
+func (s *BillingService) handleStartError(ctx context.Context, payment *model.Payment, err error) bool {
+	if errors.Is(err, ErrPaystackClient) && !errors.Is(err, ErrPaystackDuplicateReference) {
+		s.failPayment(ctx, payment, err.Error())
+		return false
+	}
+	s.scheduleVerification(ctx, payment.Reference)
+	return true
+}
+
+// scheduleVerification verifies a pending payment periodically until its status is final
+func (s *BillingService) scheduleVerification(ctx context.Context, reference string) {
+	if err := worker.NewPaymentVerificationJob(s.worker, model.PaymentVerificationPayload{TaskMeta: worker.NewTaskMeta(ctx), Reference: reference}); err != nil {
+		// the charge webhook still completes the payment
+		s.logError(ctx, err, "failed to enqueue payment verification", zap.String("reference", reference))
+	}
+}
+
+// failPayment marks a payment that paystack refused to start as failed
+func (s *BillingService) failPayment(ctx context.Context, payment *model.Payment, reason string) {
+	if len(reason) > 255 {
+		reason = reason[:255]
+	}
+	err := s.db.WithContext(ctx).Model(payment).Where("status = ?", model.PaymentPending).
+		Updates(map[string]any{"status": model.PaymentFailed, "gateway_response": reason}).Error
+	if err != nil {
+		s.logError(ctx, err, "failed to mark payment as failed", zap.String("reference", payment.Reference))
+	}
+}
+
+func (s *BillingService) logError(ctx context.Context, err error, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Error(msg, append(fields, zap.Error(err))...)
+}
@@ -0,0 +1,472 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"konnect/internal/cache"
+	"konnect/internal/config"
+	"konnect/internal/database"
+	"konnect/internal/model"
+	"konnect/internal/paystacktest"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	testPlan      = "premium_monthly"
+	testBoostPlan = "boost_30m"
+)
+
+// newTestBilling runs the billing service against the postgis database set with the TEST_DB_* variables, the redis server
+// set with the TEST_REDIS_* variables and a fake paystack api. Tests using it are skipped without a database. Boosts are
+// cached in redis and verification jobs are enqueued there, failing to enqueue them does not fail payments
+func newTestBilling(t *testing.T) (*BillingService, *paystacktest.Server) {
+	t.Helper()
+
+	host := os.Getenv("TEST_DB_HOST")
+	if host == "" {
+		t.Skip("TEST_DB_HOST is not set")
+	}
+	cfg := &config.Config{
+		DbHost:     host,
+		DbPort:     getTestEnv("TEST_DB_PORT", "5432"),
+		DbUsername: getTestEnv("TEST_DB_USERNAME", "postgres"),
+		DbPassword: getTestEnv("TEST_DB_PASSWORD", "postgres"),
+		DbName:     getTestEnv("TEST_DB_NAME", "konnect_test"),
+	}
+
+	log := newTestLogger()
+	db, err := database.New(cfg, log)
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	cacheClient := newTestCache(t)
+	client := asynq.NewClient(asynq.RedisClientOpt{Addr: cacheClient.Options().Addr, Password: cacheClient.Options().Password})
+	t.Cleanup(func() { client.Close() })
+
+	paystack, server := newTestPaystack(t)
+	boosts := NewBoostService(db, cache.NewBoosts(cacheClient, log), cfg, log)
+	return NewBillingService(db, client, paystack, boosts, cfg, log), server
+}
+
+// newTestCache connects to the redis server set with the TEST_REDIS_* variables
+func newTestCache(t *testing.T) *cache.Client {
+	t.Helper()
+
+	client, err := cache.New(&config.Config{
+		RedisAddr:     getTestEnv("TEST_REDIS_ADDR", "localhost:6379"),
+		RedisPassword: os.Getenv("TEST_REDIS_PASSWORD"),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to the test redis server: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func getTestEnv(key, fallback string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return fallback
+}
+
+// newTestUser creates a user removed with its payments after the test. The phone number is verified when a network
+// prefix is given, e.g. 24 for MTN
+func newTestUser(t *testing.T, billing *BillingService, phonePrefix string) uuid.UUID {
+	t.Helper()
+
+	id := uuid.New()
+	user := model.User{
+		Email:    id.String() + "@example.com",
+		Username: "user_" + id.String()[:8],
+		Provider: "local",
+	}
+	user.ID = id
+	if phonePrefix != "" {
+		phone := fmt.Sprintf("+233%s%07d", phonePrefix, rand.IntN(10_000_000))
+		user.PhoneNumber = &phone
+		user.PhoneVerified = true
+	}
+	if err := billing.db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	t.Cleanup(func() {
+		billing.db.Unscoped().Where("user_id = ?", id).Delete(&model.Boost{})
+		billing.db.Unscoped().Where("user_id = ?", id).Delete(&model.Payment{})
+		billing.db.Unscoped().Where("user_id = ?", id).Delete(&model.Subscription{})
+		billing.db.Unscoped().Where("id = ?", id).Delete(&model.User{})
+	})
+	return id
+}
+
+func getTestPayment(t *testing.T, billing *BillingService, reference string) model.Payment {
+	t.Helper()
+
+	var payment model.Payment
+	if err := billing.db.Where("reference = ?", reference).Take(&payment).Error; err != nil {
+		t.Fatalf("failed to get payment %s: %v", reference, err)
+	}
+	return payment
+}
+
+func getTestSubscription(t *testing.T, billing *BillingService, userID uuid.UUID) model.Subscription {
+	t.Helper()
+
+	var subscription model.Subscription
+	if err := billing.db.Where("user_id = ?", userID).Take(&subscription).Error; err != nil {
+		t.Fatalf("failed to get subscription of %s: %v", userID, err)
+	}
+	return subscription
+}
+
+func TestBillingCheckoutAndVerify(t *testing.T) {
+	billing, server := newTestBilling(t)
+	userID := newTestUser(t, billing, "")
+
+	checkout, err := billing.Checkout(context.Background(), userID, testPlan)
+	if err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+	if checkout.AuthorizationURL == "" {
+		t.Fatal("Checkout() returned no authorization url")
+	}
+
+	payment, err := billing.VerifyPayment(context.Background(), userID, checkout.Reference)
+	if err != nil {
+		t.Fatalf("VerifyPayment() error = %v", err)
+	}
+	if payment.Status != model.PaymentPending {
+		t.Fatalf("status before payment = %s, want %s", payment.Status, model.PaymentPending)
+	}
+
+	server.Complete(checkout.Reference)
+	if _, err := billing.VerifyPayment(context.Background(), userID, checkout.Reference); err != nil {
+		t.Fatalf("VerifyPayment() error = %v", err)
+	}
+	if status := getTestPayment(t, billing, checkout.Reference).Status; status != model.PaymentSuccess {
+		t.Fatalf("status after payment = %s, want %s", status, model.PaymentSuccess)
+	}
+	if subscription := getTestSubscription(t, billing, userID); !subscription.Active(time.Now()) {
+		t.Fatalf("subscription expiring at %s is not active", subscription.ExpiresAt)
+	}
+}
+
+func TestBillingBoostCheckout(t *testing.T) {
+	billing, server := newTestBilling(t)
+	userID := newTestUser(t, billing, "")
+	ctx := context.Background()
+
+	// boosts bought while another is running start when it ends
+	for range 2 {
+		checkout, err := billing.Checkout(ctx, userID, testBoostPlan)
+		if err != nil {
+			t.Fatalf("Checkout() error = %v", err)
+		}
+		server.Complete(checkout.Reference)
+		if _, err := billing.VerifyPayment(ctx, userID, checkout.Reference); err != nil {
+			t.Fatalf("VerifyPayment() error = %v", err)
+		}
+	}
+
+	boostCache := billing.boosts.boostCache
+	cacheClient := newTestCache(t)
+	t.Cleanup(func() {
+		ctx := context.Background()
+		cacheClient.ZRem(ctx, cache.GetActiveBoostsKey(), userID.String())
+		cacheClient.Del(ctx, cache.GetUserBoostsKey(userID.String()))
+	})
+
+	boosts, err := billing.boosts.GetBoosts(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetBoosts() error = %v", err)
+	}
+	if len(boosts) != 2 {
+		t.Fatalf("got %d boosts, want 2", len(boosts))
+	}
+	// latest first
+	running, next := boosts[1], boosts[0]
+	t.Cleanup(func() {
+		cacheClient.Del(context.Background(), cache.GetBoostViewsKey(running.ID.String()), cache.GetBoostViewsKey(next.ID.String()))
+	})
+	if !running.Active(time.Now()) {
+		t.Fatalf("boost from %s to %s is not running", running.StartsAt, running.EndsAt)
+	}
+	if !next.StartsAt.Equal(running.EndsAt) {
+		t.Fatalf("next boost starts at %s, want the end of the running boost %s", next.StartsAt, running.EndsAt)
+	}
+
+	boosted, err := boostCache.GetBoosted(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("GetBoosted() error = %v", err)
+	}
+	if !slices.Contains(boosted, userID.String()) {
+		t.Fatal("user is not boosted")
+	}
+
+	// views count towards the running boost only
+	billing.boosts.recordViews(ctx, []string{userID.String()})
+	for _, tt := range []struct {
+		boost model.Boost
+		want  int64
+	}{{running, 1}, {next, 0}} {
+		views, err := boostCache.GetViews(ctx, tt.boost.ID.String())
+		if err != nil {
+			t.Fatalf("GetViews() error = %v", err)
+		}
+		if views != tt.want {
+			t.Errorf("views of boost starting at %s = %d, want %d", tt.boost.StartsAt, views, tt.want)
+		}
+	}
+}
+
+func TestBillingMobileMoneyOTP(t *testing.T) {
+	billing, _ := newTestBilling(t)
+	userID := newTestUser(t, billing, "20")
+
+	charge, err := billing.ChargeMobileMoney(context.Background(), userID, testPlan)
+	if err != nil {
+		t.Fatalf("ChargeMobileMoney() error = %v", err)
+	}
+	if charge.Status != model.PaystackSendOTP {
+		t.Fatalf("charge status = %s, want %s", charge.Status, model.PaystackSendOTP)
+	}
+
+	if _, err := billing.SubmitMobileMoneyOTP(context.Background(), userID, charge.Reference, "000000"); !errors.Is(err, ErrPaystackClient) {
+		t.Fatalf("SubmitMobileMoneyOTP() with a wrong otp error = %v, want %v", err, ErrPaystackClient)
+	}
+	if status := getTestPayment(t, billing, charge.Reference).Status; status != model.PaymentPending {
+		t.Fatalf("status after a wrong otp = %s, want %s", status, model.PaymentPending)
+	}
+
+	submitted, err := billing.SubmitMobileMoneyOTP(context.Background(), userID, charge.Reference, paystacktest.OTP)
+	if err != nil {
+		t.Fatalf("SubmitMobileMoneyOTP() error = %v", err)
+	}
+	if submitted.Status != model.PaystackSuccess {
+		t.Fatalf("charge status after otp = %s, want %s", submitted.Status, model.PaystackSuccess)
+	}
+	if status := getTestPayment(t, billing, charge.Reference).Status; status != model.PaymentSuccess {
+		t.Fatalf("status after otp = %s, want %s", status, model.PaymentSuccess)
+	}
+
+	if _, err := billing.SubmitMobileMoneyOTP(context.Background(), userID, charge.Reference, paystacktest.OTP); !errors.Is(err, ErrPaymentNotPending) {
+		t.Fatalf("SubmitMobileMoneyOTP() on a paid charge error = %v, want %v", err, ErrPaymentNotPending)
+	}
+}
+
+func TestBillingMobileMoneyPolling(t *testing.T) {
+	billing, server := newTestBilling(t)
+	userID := newTestUser(t, billing, "24")
+	ctx := context.Background()
+
+	charge, err := billing.ChargeMobileMoney(ctx, userID, testPlan)
+	if err != nil {
+		t.Fatalf("ChargeMobileMoney() error = %v", err)
+	}
+	if charge.Status != model.PaystackPayOffline {
+		t.Fatalf("charge status = %s, want %s", charge.Status, model.PaystackPayOffline)
+	}
+
+	done, err := billing.VerifyPendingPayment(ctx, charge.Reference)
+	if err != nil || done {
+		t.Fatalf("VerifyPendingPayment() before approval = %t, %v, want false", done, err)
+	}
+
+	// the customer approves the charge on their phone
+	server.Complete(charge.Reference)
+	done, err = billing.VerifyPendingPayment(ctx, charge.Reference)
+	if err != nil || !done {
+		t.Fatalf("VerifyPendingPayment() after approval = %t, %v, want true", done, err)
+	}
+	if status := getTestPayment(t, billing, charge.Reference).Status; status != model.PaymentSuccess {
+		t.Fatalf("status after approval = %s, want %s", status, model.PaymentSuccess)
+	}
+}
+
+func TestBillingLostChargeResponse(t *testing.T) {
+	billing, server := newTestBilling(t)
+	userID := newTestUser(t, billing, "20")
+	ctx := context.Background()
+
+	// paystack creates the charge but the response is lost, the payment waits for verification
+	server.DropNext(1)
+	charge, err := billing.ChargeMobileMoney(ctx, userID, testPlan)
+	if err != nil {
+		t.Fatalf("ChargeMobileMoney() error = %v", err)
+	}
+	if charge.Status != model.PaystackPending {
+		t.Fatalf("charge status = %s, want %s", charge.Status, model.PaystackPending)
+	}
+	if status := getTestPayment(t, billing, charge.Reference).Status; status != model.PaymentPending {
+		t.Fatalf("status after a lost response = %s, want %s", status, model.PaymentPending)
+	}
+
+	if _, err := billing.SubmitMobileMoneyOTP(ctx, userID, charge.Reference, paystacktest.OTP); err != nil {
+		t.Fatalf("SubmitMobileMoneyOTP() error = %v", err)
+	}
+	if status := getTestPayment(t, billing, charge.Reference).Status; status != model.PaymentSuccess {
+		t.Fatalf("status after otp = %s, want %s", status, model.PaymentSuccess)
+	}
+}
+
+func TestBillingStartErrors(t *testing.T) {
+	billing, server := newTestBilling(t)
+	userID := newTestUser(t, billing, "")
+	plan, err := billing.getPlan(context.Background(), testPlan)
+	if err != nil {
+		t.Fatalf("getPlan() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		// starts the paystack transaction of the reference and returns the error of the request
+		start func(reference string) error
+		want  model.PaymentStatus
+	}{
+		{
+			name: "rejected",
+			start: func(reference string) error {
+				_, err := billing.paystack.InitiateTransaction(reference, plan.Amount, plan.Currency, "", "", nil)
+				return err
+			},
+			want: model.PaymentFailed,
+		},
+		{
+			name: "server error",
+			start: func(reference string) error {
+				server.FailNext(paystackRetries + 1)
+				_, err := billing.paystack.InitiateTransaction(reference, plan.Amount, plan.Currency, "ama@example.com", "", nil)
+				return err
+			},
+			want: model.PaymentPending,
+		},
+		{
+			// a retry of a request whose response was lost
+			name: "duplicate reference",
+			start: func(reference string) error {
+				if _, err := billing.paystack.InitiateTransaction(reference, plan.Amount, plan.Currency, "ama@example.com", "", nil); err != nil {
+					return err
+				}
+				_, err := billing.paystack.InitiateTransaction(reference, plan.Amount, plan.Currency, "ama@example.com", "", nil)
+				return err
+			},
+			want: model.PaymentPending,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &model.Payment{
+				UserID:    userID,
+				PlanID:    plan.ID,
+				Reference: billing.paystack.GenerateReference(),
+				Amount:    plan.Amount,
+				Currency:  plan.Currency,
+				Channel:   model.CheckoutChannel,
+				Status:    model.PaymentPending,
+			}
+			if err := billing.db.Create(payment).Error; err != nil {
+				t.Fatalf("failed to create payment: %v", err)
+			}
+
+			err := tt.start(payment.Reference)
+			if err == nil {
+				t.Fatal("starting the transaction did not fail")
+			}
+			pending := billing.handleStartError(context.Background(), payment, err)
+			if status := getTestPayment(t, billing, payment.Reference).Status; status != tt.want || pending != (tt.want == model.PaymentPending) {
+				t.Fatalf("handleStartError(%v) = %t with status %s, want status %s", err, pending, status, tt.want)
+			}
+			if !pending {
+				return
+			}
+
+			// payments paystack does not know stay pending, others are settled once paystack confirms them
+			if !server.Complete(payment.Reference) {
+				done, err := billing.VerifyPendingPayment(context.Background(), payment.Reference)
+				if err != nil || done {
+					t.Fatalf("VerifyPendingPayment() of an unknown transaction = %t, %v, want false", done, err)
+				}
+				return
+			}
+			done, err := billing.VerifyPendingPayment(context.Background(), payment.Reference)
+			if err != nil || !done {
+				t.Fatalf("VerifyPendingPayment() = %t, %v, want true", done, err)
+			}
+			if status := getTestPayment(t, billing, payment.Reference).Status; status != model.PaymentSuccess {
+				t.Fatalf("status after verification = %s, want %s", status, model.PaymentSuccess)
+			}
+		})
+	}
+}
+
+func TestPaystackWebhookDeduplication(t *testing.T) {
+	billing, server := newTestBilling(t)
+	if err := billing.worker.Ping(); err != nil {
+		t.Skipf("test redis is not reachable: %v", err)
+	}
+	webhooks := NewWebhookService(billing.db, billing.worker, billing.paystack, billing, newTestLogger())
+	userID := newTestUser(t, billing, "")
+	ctx := context.Background()
+
+	checkout, err := billing.Checkout(ctx, userID, testPlan)
+	if err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+	server.Complete(checkout.Reference)
+	payload, signature, err := server.Webhook(model.PaystackChargeSuccess, checkout.Reference)
+	if err != nil {
+		t.Fatalf("Webhook() error = %v", err)
+	}
+	transaction, _ := server.Transaction(checkout.Reference)
+	eventID := fmt.Sprintf("%s:%d", model.PaystackChargeSuccess, transaction.ID)
+	t.Cleanup(func() {
+		billing.db.Unscoped().Where("provider = ? AND event_id = ?", paystackProvider, eventID).Delete(&model.WebhookEvent{})
+	})
+
+	// paystack redelivers events until they are acknowledged
+	for range 2 {
+		if err := webhooks.ReceivePaystackEvent(ctx, payload, signature, testPaystackOrigin); err != nil {
+			t.Fatalf("ReceivePaystackEvent() error = %v", err)
+		}
+	}
+	var events []model.WebhookEvent
+	if err := billing.db.Where("provider = ? AND event_id = ?", paystackProvider, eventID).Find(&events).Error; err != nil {
+		t.Fatalf("failed to get webhook events: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("stored %d events for a redelivered event, want 1", len(events))
+	}
+
+	if err := webhooks.ProcessWebhookEvent(ctx, events[0].ID); err != nil {
+		t.Fatalf("ProcessWebhookEvent() error = %v", err)
+	}
+	if status := getTestPayment(t, billing, checkout.Reference).Status; status != model.PaymentSuccess {
+		t.Fatalf("status after charge.success = %s, want %s", status, model.PaymentSuccess)
+	}
+	expiresAt := getTestSubscription(t, billing, userID).ExpiresAt
+
+	// a redelivery of a processed event grants nothing more
+	if err := webhooks.ReceivePaystackEvent(ctx, payload, signature, testPaystackOrigin); err != nil {
+		t.Fatalf("ReceivePaystackEvent() error = %v", err)
+	}
+	if err := webhooks.ProcessWebhookEvent(ctx, events[0].ID); err != nil {
+		t.Fatalf("ProcessWebhookEvent() error = %v", err)
+	}
+	if got := getTestSubscription(t, billing, userID).ExpiresAt; !got.Equal(expiresAt) {
+		t.Fatalf("subscription expiring at %s after a redelivery, want %s", got, expiresAt)
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"konnect/internal/cache"
+	"konnect/internal/config"
+	"konnect/internal/database"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type BoostService struct {
+	db         *database.DB
+	boostCache *cache.BoostCache
+	cfg        *config.Config
+	logger     *zap.Logger
+}
+
+func NewBoostService(db *database.DB, boostCache *cache.BoostCache, cfg *config.Config, logger *logger.Logger) *BoostService {
+	return &BoostService{
+		db:         db,
+		boostCache: boostCache,
+		cfg:        cfg,
+		logger:     logger.With(zap.String("component", "boost_service")),
+	}
+}
+
+// GetBoosts returns the latest boosts of a user with their performance. Boosts that have not expired report their
+// performance so far
+func (s *BoostService) GetBoosts(ctx context.Context, userID uuid.UUID) ([]model.Boost, error) {
+	var boosts []model.Boost
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("starts_at DESC").Limit(20).Find(&boosts).Error; err != nil {
+		s.logError(ctx, err, "failed to get boosts", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+
+	cacheCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	now := time.Now()
+	for i := range boosts {
+		boost := &boosts[i]
+		if boost.Expired || !boost.Active(now) {
+			continue
+		}
+		likes, err := s.countLikesReceived(s.db.WithContext(ctx), boost, now)
+		if err != nil {
+			s.logError(ctx, err, "failed to count boost likes", zap.String("boost_id", boost.ID.String()))
+			return nil, err
+		}
+		boost.LikesReceived = likes
+		if boost.Views, err = s.boostCache.GetViews(cacheCtx, boost.ID.String()); err != nil {
+			logger.FromContext(ctx, s.logger).Warn("failed to get boost views", zap.Error(err), zap.String("boost_id", boost.ID.String()))
+		}
+	}
+	return boosts, nil
+}
+
+// ExpireBoosts records the performance of boosts that ended and refreshes the boosted users used for ranking, adding
+// boosts that could not be cached when bought. It implements the worker.BoostExpirer interface
+func (s *BoostService) ExpireBoosts(ctx context.Context) error {
+	now := time.Now()
+
+	var ended []model.Boost
+	if err := s.db.WithContext(ctx).Where("expired = ? AND ends_at <= ?", false, now).Order("ends_at").Find(&ended).Error; err != nil {
+		s.logError(ctx, err, "failed to get ended boosts")
+		return err
+	}
+	for i := range ended {
+		if err := s.expire(ctx, &ended[i]); err != nil {
+			return err
+		}
+	}
+
+	// boosts of the users boosted now, including the ones chained after their running boost
+	var boosted []model.Boost
+	err := s.db.WithContext(ctx).Where("ends_at > ? AND user_id IN (?)", now,
+		s.db.Model(&model.Boost{}).Select("user_id").Where("starts_at <= ? AND ends_at > ?", now, now)).
+		Order("ends_at").Find(&boosted).Error
+	if err != nil {
+		s.logError(ctx, err, "failed to get active boosts")
+		return err
+	}
+	for _, boost := range boosted {
+		if err := s.boostCache.SetBoost(ctx, boost.UserID.String(), boost.ID.String(), boost.EndsAt); err != nil {
+			s.logError(ctx, err, "failed to cache boost", zap.String("boost_id", boost.ID.String()))
+			return err
+		}
+	}
+	if err := s.boostCache.RemoveEnded(ctx, now); err != nil {
+		s.logError(ctx, err, "failed to remove ended boosts")
+		return err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("Expired boosts", zap.Int("expired", len(ended)), zap.Int("active", len(boosted)))
+	return nil
+}
+
+// createBoost schedules the boost bought by a payment. It starts now or when the running boost of the user ends
+func (s *BoostService) createBoost(tx *gorm.DB, payment *model.Payment, plan *model.Plan) (*model.Boost, error) {
+	startsAt := time.Now()
+
+	var latest model.Boost
+	err := tx.Where("user_id = ? AND ends_at > ?", payment.UserID, startsAt).Order("ends_at DESC").Take(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		startsAt = latest.EndsAt
+	}
+
+	boost := &model.Boost{
+		UserID:    payment.UserID,
+		PaymentID: payment.ID,
+		StartsAt:  startsAt,
+		EndsAt:    startsAt.Add(time.Duration(plan.BoostMinutes) * time.Minute),
+	}
+	if err := tx.Create(boost).Error; err != nil {
+		return nil, err
+	}
+	return boost, nil
+}
+
+// activate adds a new boost to the boosted users. Boosts of a user run back to back, so the user stays boosted until
+// the end of their latest boost. Failures are recovered by the expiry job
+func (s *BoostService) activate(ctx context.Context, boost *model.Boost) {
+	cacheCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	if err := s.boostCache.SetBoost(cacheCtx, boost.UserID.String(), boost.ID.String(), boost.EndsAt); err != nil {
+		logger.FromContext(ctx, s.logger).Warn("failed to cache boost", zap.Error(err), zap.String("boost_id", boost.ID.String()))
+	}
+}
+
+// boostedUsers returns the users boosted now. Ranking goes on without boosts when they cannot be read
+func (s *BoostService) boostedUsers(ctx context.Context) []string {
+	boosted, err := s.boostCache.GetBoosted(ctx, time.Now())
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Warn("failed to get boosted users", zap.Error(err))
+		return nil
+	}
+	return boosted
+}
+
+// multiplier is the factor the distance to boosted users is divided by when ranking
+func (s *BoostService) multiplier() float64 {
+	return float64(max(s.cfg.BoostMultiplier, 1))
+}
+
+// recordViews counts a view of the boost of each listed user that is boosted
+func (s *BoostService) recordViews(ctx context.Context, userIDs []string) {
+	cacheCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	if err := s.boostCache.RecordViews(cacheCtx, userIDs, time.Now()); err != nil {
+		logger.FromContext(ctx, s.logger).Warn("failed to record boost views", zap.Error(err))
+	}
+}
+
+// expire stores the final performance of an ended boost
+func (s *BoostService) expire(ctx context.Context, boost *model.Boost) error {
+	likes, err := s.countLikesReceived(s.db.WithContext(ctx), boost, boost.EndsAt)
+	if err != nil {
+		s.logError(ctx, err, "failed to count boost likes", zap.String("boost_id", boost.ID.String()))
+		return err
+	}
+	views, err := s.boostCache.TakeViews(ctx, boost.UserID.String(), boost.ID.String())
+	if err != nil {
+		s.logError(ctx, err, "failed to get boost views", zap.String("boost_id", boost.ID.String()))
+		return err
+	}
+
+	err = s.db.WithContext(ctx).Model(boost).Updates(map[string]any{"views": views, "likes_received": likes, "expired": true}).Error
+	if err != nil {
+		s.logError(ctx, err, "failed to expire boost", zap.String("boost_id", boost.ID.String()))
+	}
+	return err
+}
+
+// countLikesReceived counts the likes the user of a boost received from its start until the given time
+func (s *BoostService) countLikesReceived(db *gorm.DB, boost *model.Boost, until time.Time) (int64, error) {
+	var likes int64
+	err := db.Model(&model.Swipe{}).
+		Where("swipee_id = ? AND swipe_type = ? AND created_at >= ? AND created_at < ?", boost.UserID, model.Like, boost.StartsAt, until).
+		Count(&likes).Error
+	return likes, err
+}
+
+func (s *BoostService) logError(ctx context.Context, err error, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Error(msg, append(fields, zap.Error(err))...)
+}
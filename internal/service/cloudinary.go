@@ -43,11 +43,11 @@ func (s *CloudinaryService) UploadImage(ctx context.Context, file interface{}, f
 
 	resp, err := s.cld.Upload.Upload(ctx, file, params)
 	if err != nil {
-		s.logger.Error("failed to upload image", zap.String("filename", filename), zap.Error(err))
+		logger.FromContext(ctx, s.logger).Error("failed to upload image", zap.String("filename", filename), zap.Error(err))
 		return "", "", err
 	}
 
-	s.logger.Info("successfully uploaded image",
+	logger.FromContext(ctx, s.logger).Info("successfully uploaded image",
 		zap.String("publicID", resp.PublicID))
 
 	return resp.SecureURL, resp.PublicID, nil
@@ -55,10 +55,10 @@ func (s *CloudinaryService) UploadImage(ctx context.Context, file interface{}, f
 
 func (s *CloudinaryService) DeleteImage(ctx context.Context, publicID string) error {
 	if _, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{PublicID: publicID}); err != nil {
-		s.logger.Error("failed to delete image", zap.String("publicID", publicID), zap.Error(err))
+		logger.FromContext(ctx, s.logger).Error("failed to delete image", zap.String("publicID", publicID), zap.Error(err))
 		return err
 	}
 
-	s.logger.Info("successfully deleted image", zap.String("publicId", publicID))
+	logger.FromContext(ctx, s.logger).Info("successfully deleted image", zap.String("publicId", publicID))
 	return nil
 }
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"konnect/internal/database"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrDeviceNotFound = errors.New("device not found")
+)
+
+type DeviceService struct {
+	db     *database.DB
+	logger *zap.Logger
+}
+
+func NewDeviceService(db *database.DB, logger *logger.Logger) *DeviceService {
+	return &DeviceService{
+		db:     db,
+		logger: logger.With(zap.String("component", "device_service")),
+	}
+}
+
+// RegisterDevice stores a push token for the user. A token moving to another account is reassigned to the new owner
+func (s *DeviceService) RegisterDevice(ctx context.Context, userID uuid.UUID, token string, platform model.DevicePlatform) (*model.DeviceToken, error) {
+	device := &model.DeviceToken{
+		UserID:     userID,
+		Token:      token,
+		Platform:   platform,
+		LastSeenAt: time.Now(),
+	}
+
+	// upsert device by token
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "last_seen_at", "updated_at"}),
+	}, clause.Returning{}).Create(device).Error; err != nil {
+		s.logError(ctx, err, "failed to register device", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// UnregisterDevice removes a push token owned by the user
+func (s *DeviceService) UnregisterDevice(ctx context.Context, userID uuid.UUID, token string) error {
+	res := s.db.WithContext(ctx).Unscoped().Where("user_id = ? AND token = ?", userID, token).Delete(&model.DeviceToken{})
+	if res.Error != nil {
+		s.logError(ctx, res.Error, "failed to unregister device", zap.String("user_id", userID.String()))
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
+
+// GetUserDeviceTokens returns all push tokens of a user. It implements the worker.DeviceStore interface
+func (s *DeviceService) GetUserDeviceTokens(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var tokens []string
+	if err := s.db.WithContext(ctx).Model(&model.DeviceToken{}).Where("user_id = ?", userID).Pluck("token", &tokens).Error; err != nil {
+		s.logError(ctx, err, "failed to get user device tokens", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// DeleteDeviceTokens prunes tokens that were rejected by the push provider
+func (s *DeviceService) DeleteDeviceTokens(ctx context.Context, tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	if err := s.db.WithContext(ctx).Unscoped().Where("token IN ?", tokens).Delete(&model.DeviceToken{}).Error; err != nil {
+		s.logError(ctx, err, "failed to delete device tokens", zap.Int("count", len(tokens)))
+		return err
+	}
+	logger.FromContext(ctx, s.logger).Info("pruned invalid device tokens", zap.Int("count", len(tokens)))
+	return nil
+}
+
+func (s *DeviceService) logError(ctx context.Context, err error, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Error(msg, append(fields, zap.Error(err))...)
+}
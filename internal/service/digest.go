@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"konnect/internal/database"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/worker"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// digestBatchSize is the number of digest recipients summarized per query
+const digestBatchSize = 100
+
+// DigestSummary is the activity of a user since their last digest
+type DigestSummary struct {
+	Likes    int64
+	Matches  int64
+	Messages int64
+}
+
+func (d DigestSummary) Empty() bool {
+	return d.Likes == 0 && d.Matches == 0 && d.Messages == 0
+}
+
+type DigestService struct {
+	db     *database.DB
+	worker *asynq.Client
+	logger *zap.Logger
+}
+
+func NewDigestService(db *database.DB, worker *asynq.Client, logger *logger.Logger) *DigestService {
+	return &DigestService{
+		db:     db,
+		worker: worker,
+		logger: logger.With(zap.String("component", "digest_service")),
+	}
+}
+
+// userCount is a per user count from an aggregate query
+type userCount struct {
+	UserID uuid.UUID
+	Count  int64
+}
+
+// SendDigests enqueues a digest email for every user in digest mode with the given frequency who had activity in the period.
+// Users are marked once their digest is enqueued, so a retried run only sends the digests it missed. It implements the
+// worker.DigestSender interface
+func (s *DigestService) SendDigests(ctx context.Context, frequency model.DigestFrequency) error {
+	now := time.Now()
+	since := now.Add(-frequency.Period())
+	// digests of the previous run are older than half a period, digests of an earlier attempt of this run are newer
+	sentAfter := now.Add(-frequency.Period() / 2)
+	var sent, skipped int
+
+	// fetch recipients in batches
+	var recipients []model.NotificationPreference
+	err := s.db.WithContext(ctx).
+		Preload("User").
+		Where("delivery_mode = ? AND digest_frequency = ? AND email_unsubscribed = ?", model.DigestDelivery, frequency, false).
+		Where("last_digest_at IS NULL OR last_digest_at < ?", sentAfter).
+		FindInBatches(&recipients, digestBatchSize, func(tx *gorm.DB, batch int) error {
+			userIDs := make([]uuid.UUID, 0, len(recipients))
+			for _, r := range recipients {
+				userIDs = append(userIDs, r.UserID)
+			}
+
+			summaries, err := s.summarize(ctx, userIDs, since)
+			if err != nil {
+				return err
+			}
+
+			sentIDs := make([]uuid.UUID, 0, len(recipients))
+			for _, r := range recipients {
+				summary := summaries[r.UserID]
+				// deleted users are not preloaded
+				if r.User == nil || summary.Empty() {
+					skipped++
+					continue
+				}
+
+				err := worker.NewEmailDeliveryJob(s.worker, model.EmailPayload{
+					Email:      r.User.Email,
+					UserID:     r.UserID,
+					Type:       model.DigestNotification,
+					TemplateID: string(model.DigestNotification),
+					Locale:     r.User.Locale,
+					Data: map[string]any{
+						"frequency": string(frequency),
+						"likes":     summary.Likes,
+						"matches":   summary.Matches,
+						"messages":  summary.Messages,
+					},
+				})
+				// a failed user must not resend the digest to the whole batch on retry
+				if err != nil {
+					s.logError(ctx, err, "failed to enqueue digest email", zap.String("user_id", r.UserID.String()))
+					skipped++
+					continue
+				}
+				sentIDs = append(sentIDs, r.UserID)
+			}
+			sent += len(sentIDs)
+
+			if len(sentIDs) == 0 {
+				return nil
+			}
+			return s.db.WithContext(ctx).Model(&model.NotificationPreference{}).
+				Where("user_id IN ?", sentIDs).
+				Update("last_digest_at", now).Error
+		}).Error
+
+	if err != nil {
+		s.logError(ctx, err, "failed to send digests", zap.String("frequency", string(frequency)))
+		return err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("digests sent", zap.String("frequency", string(frequency)), zap.Int("sent", sent), zap.Int("skipped", skipped))
+	return nil
+}
+
+// summarize counts new likes and matches since the given time and unread messages in matches for a batch of users
+func (s *DigestService) summarize(ctx context.Context, userIDs []uuid.UUID, since time.Time) (map[uuid.UUID]DigestSummary, error) {
+	summaries := make(map[uuid.UUID]DigestSummary, len(userIDs))
+	db := s.db.WithContext(ctx)
+
+	var likes []userCount
+	if err := db.Model(&model.Swipe{}).
+		Select("swipee_id AS user_id, COUNT(*) AS count").
+		Where("swipee_id IN ? AND swipe_type = ? AND created_at >= ?", userIDs, model.Like, since).
+		Group("swipee_id").
+		Scan(&likes).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range likes {
+		summary := summaries[c.UserID]
+		summary.Likes = c.Count
+		summaries[c.UserID] = summary
+	}
+
+	// a user can be on either side of a match
+	for _, column := range []string{"user1_id", "user2_id"} {
+		var matches []userCount
+		if err := db.Model(&model.Match{}).
+			Select(column+" AS user_id, COUNT(*) AS count").
+			Where(column+" IN ? AND created_at >= ?", userIDs, since).
+			Group(column).
+			Scan(&matches).Error; err != nil {
+			return nil, err
+		}
+		for _, c := range matches {
+			summary := summaries[c.UserID]
+			summary.Matches += c.Count
+			summaries[c.UserID] = summary
+		}
+	}
+
+	// unread messages the other side of the user's matches sent, regardless of age
+	for _, column := range []string{"user1_id", "user2_id"} {
+		var messages []userCount
+		if err := db.Model(&model.Message{}).
+			Select("matches."+column+" AS user_id, COUNT(*) AS count").
+			Joins("JOIN matches ON matches.id = messages.match_id AND matches.deleted_at IS NULL").
+			Where("matches."+column+" IN ? AND messages.sender_id <> matches."+column+" AND messages.read_at IS NULL", userIDs).
+			Group("matches." + column).
+			Scan(&messages).Error; err != nil {
+			return nil, err
+		}
+		for _, c := range messages {
+			summary := summaries[c.UserID]
+			summary.Messages += c.Count
+			summaries[c.UserID] = summary
+		}
+	}
+
+	return summaries, nil
+}
+
+func (s *DigestService) logError(ctx context.Context, err error, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Error(msg, append(fields, zap.Error(err))...)
+}
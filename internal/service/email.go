@@ -1,11 +1,15 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"konnect/internal/config"
+	"konnect/internal/logger"
+	"konnect/internal/worker"
 
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type EmailService struct {
@@ -17,10 +21,30 @@ var (
 	ErrCourierEmailServer = errors.New("courier email server error")
 )
 
+// NewEmailDispatcher returns the email transport selected by the EMAIL_TRANSPORT config
+func NewEmailDispatcher(cfg *config.Config, logger *logger.Logger) (worker.EmailDispatcher, error) {
+	switch cfg.EmailTransport {
+	case "courier":
+		if cfg.CourierAPIKey == "" {
+			return nil, errors.New("courier api key not set")
+		}
+		return NewEmailService(cfg), nil
+	case "smtp":
+		return NewSMTPEmailService(cfg, logger)
+	case "outbox":
+		return NewOutboxEmailService(cfg, logger)
+	default:
+		return nil, fmt.Errorf("unknown email transport: %s", cfg.EmailTransport)
+	}
+}
+
+// NewEmailService creates the courier email transport
 func NewEmailService(cfg *config.Config) *EmailService {
 	// base client with auth header
 	httpClient := resty.New().SetBaseURL("https://api.courier.com")
 	httpClient.SetHeader("Authorization", "Bearer "+cfg.CourierAPIKey)
+	// courier calls show up in the trace of the email task
+	httpClient.SetTransport(otelhttp.NewTransport(httpClient.GetClient().Transport))
 
 	return &EmailService{
 		cfg:        cfg,
@@ -28,21 +52,35 @@ func NewEmailService(cfg *config.Config) *EmailService {
 	}
 }
 
-func (s *EmailService) Send(email string, message string, subject string) error {
+// Send delivers an already rendered email. Courier's inline content only carries plain text so the html body is not used.
+// The unsubscribe url is sent in the one-click unsubscribe headers of rfc 8058
+func (s *EmailService) Send(ctx context.Context, email string, subject string, html string, text string, unsubscribeURL string) error {
 	// courier email without template payload
-	body := map[string]any{
-		"message": map[string]any{
-			"to": map[string]string{
-				"email": email,
-			},
-			"content": map[string]string{
-				"title": subject,
-				"body":  message,
-			},
+	message := map[string]any{
+		"to": map[string]string{
+			"email": email,
+		},
+		"content": map[string]string{
+			"title": subject,
+			"body":  text,
 		},
 	}
+	if unsubscribeURL != "" {
+		message["channels"] = map[string]any{
+			"email": map[string]any{
+				"override": map[string]any{
+					"headers": map[string]string{
+						"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+						"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+					},
+				},
+			},
+		}
+	}
+	body := map[string]any{"message": message}
 
 	res, err := s.httpClient.R().
+		SetContext(ctx).
 		SetBody(body).
 		Post("/send")
 
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"konnect/internal/config"
+	"konnect/internal/logger"
+	"konnect/internal/worker"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	fcmScope   = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmTimeout = 10 * time.Second
+)
+
+var (
+	ErrFCMServer = errors.New("fcm push server error")
+)
+
+// fcm error codes for tokens that will never be deliverable again
+var fcmInvalidTokenCodes = map[string]struct{}{
+	"UNREGISTERED":       {},
+	"SENDER_ID_MISMATCH": {},
+}
+
+type fcmErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// FCMService sends push notifications through the firebase cloud messaging HTTP v1 API. iOS devices are reached through FCM's APNs bridge
+type FCMService struct {
+	projectID  string
+	httpClient *resty.Client
+	logger     *zap.Logger
+}
+
+func NewFCMService(cfg *config.Config, logger *logger.Logger) (*FCMService, error) {
+	credentials, err := os.ReadFile(cfg.FCMCredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fcm credentials: %w", err)
+	}
+
+	creds, err := google.CredentialsFromJSON(context.Background(), credentials, fcmScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fcm credentials: %w", err)
+	}
+
+	projectID := cfg.FCMProjectID
+	if projectID == "" {
+		projectID = creds.ProjectID
+	}
+	if projectID == "" {
+		return nil, errors.New("fcm project id not set")
+	}
+
+	// base client authenticated with short-lived oauth tokens from the service account
+	httpClient := resty.NewWithClient(oauth2.NewClient(context.Background(), creds.TokenSource)).
+		SetBaseURL("https://fcm.googleapis.com/v1/projects/" + projectID).
+		SetTimeout(fcmTimeout)
+
+	return &FCMService{
+		projectID:  projectID,
+		httpClient: httpClient,
+		logger:     logger.With(zap.String("component", "fcm_service")),
+	}, nil
+}
+
+// Send pushes a notification to a device. The request is cancelled with the context of the push task
+func (s *FCMService) Send(ctx context.Context, token string, title string, body string, data map[string]string) error {
+	message := map[string]any{
+		"token": token,
+		"notification": map[string]string{
+			"title": title,
+			"body":  body,
+		},
+		"data": data,
+		"android": map[string]any{
+			"priority": "high",
+		},
+		"apns": map[string]any{
+			"headers": map[string]string{
+				"apns-priority": "10",
+			},
+			"payload": map[string]any{
+				"aps": map[string]any{
+					"sound": "default",
+				},
+			},
+		},
+	}
+
+	var errResp fcmErrorResponse
+	res, err := s.httpClient.R().
+		SetContext(ctx).
+		SetBody(map[string]any{"message": message}).
+		SetError(&errResp).
+		Post("/messages:send")
+
+	// server error. request possibly hanging
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFCMServer, err)
+	}
+	if res.IsError() {
+		if isInvalidFCMToken(res.StatusCode(), &errResp) {
+			logger.FromContext(ctx, s.logger).Warn("push token rejected by fcm", zap.String("status", errResp.Error.Status), zap.String("message", errResp.Error.Message))
+			return fmt.Errorf("%w: %s", worker.ErrInvalidPushToken, errResp.Error.Message)
+		}
+		return fmt.Errorf("%w: %s", ErrFCMServer, errResp.Error.Message)
+	}
+	return nil
+}
+
+// isInvalidFCMToken reports whether fcm rejected the request because of the device token itself
+func isInvalidFCMToken(statusCode int, errResp *fcmErrorResponse) bool {
+	for _, detail := range errResp.Error.Details {
+		if _, ok := fcmInvalidTokenCodes[detail.ErrorCode]; ok {
+			return true
+		}
+	}
+	return statusCode == http.StatusBadRequest &&
+		errResp.Error.Status == "INVALID_ARGUMENT" &&
+		strings.Contains(errResp.Error.Message, "registration token")
+}
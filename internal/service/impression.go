@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"konnect/internal/cache"
+	"konnect/internal/database"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+// days flushed to postgres per batch
+const impressionFlushBatch = 500
+
+type ImpressionService struct {
+	db              *database.DB
+	impressionCache *cache.ImpressionCache
+	logger          *zap.Logger
+}
+
+func NewImpressionService(db *database.DB, impressionCache *cache.ImpressionCache, logger *logger.Logger) *ImpressionService {
+	return &ImpressionService{
+		db:              db,
+		impressionCache: impressionCache,
+		logger:          logger.With(zap.String("component", "impression_service")),
+	}
+}
+
+// GetViewers estimates the distinct users the profile of a user was shown to over the last days. Postgres is used when
+// redis is unavailable, there viewers on several days are counted once per day
+func (s *ImpressionService) GetViewers(ctx context.Context, userID uuid.UUID, days int) (*model.ProfileViewsResponse, error) {
+	cacheCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	now := time.Now()
+	viewers, err := s.impressionCache.CountViewers(cacheCtx, userID.String(), days, now)
+	if err == nil {
+		return &model.ProfileViewsResponse{Days: days, Viewers: viewers}, nil
+	}
+	logger.FromContext(ctx, s.logger).Warn("failed to count viewers in redis, falling back to postgres", zap.Error(err), zap.String("user_id", userID.String()))
+
+	since := now.UTC().AddDate(0, 0, -(days - 1)).Format(time.DateOnly)
+	err = s.db.WithContext(ctx).Model(&model.ProfileImpression{}).Select("COALESCE(SUM(viewers), 0)").
+		Where("user_id = ? AND day >= ?", userID, since).Scan(&viewers).Error
+	if err != nil {
+		s.logError(ctx, err, "failed to count viewers", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+	return &model.ProfileViewsResponse{Days: days, Viewers: viewers}, nil
+}
+
+// FlushImpressions writes the daily viewers of profiles shown since the last flush to postgres. Counts are totals of
+// the day, so flushing a day again is harmless. It implements the worker.ImpressionFlusher interface
+func (s *ImpressionService) FlushImpressions(ctx context.Context) error {
+	var flushed int
+	for {
+		impressions, err := s.impressionCache.PopPending(ctx, impressionFlushBatch)
+		if err != nil {
+			s.logError(ctx, err, "failed to get pending impressions")
+			return err
+		}
+		if len(impressions) == 0 {
+			break
+		}
+
+		if err := s.flush(ctx, impressions); err != nil {
+			if requeueErr := s.impressionCache.RequeuePending(ctx, impressions); requeueErr != nil {
+				s.logError(ctx, requeueErr, "failed to requeue impressions", zap.Int("count", len(impressions)))
+			}
+			return err
+		}
+		flushed += len(impressions)
+	}
+
+	logger.FromContext(ctx, s.logger).Info("Flushed impressions", zap.Int("count", flushed))
+	return nil
+}
+
+// recordImpressions logs that the profiles were shown to the viewer. Failures only cost some impressions
+func (s *ImpressionService) recordImpressions(ctx context.Context, viewerID uuid.UUID, userIDs []string) {
+	cacheCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	if err := s.impressionCache.RecordImpressions(cacheCtx, viewerID.String(), userIDs, time.Now()); err != nil {
+		logger.FromContext(ctx, s.logger).Warn("failed to record impressions", zap.Error(err), zap.String("viewer_id", viewerID.String()))
+	}
+}
+
+// seenProfiles returns the profiles shown to the viewer during the last week. Nothing is left out of the feed when they
+// cannot be read
+func (s *ImpressionService) seenProfiles(ctx context.Context, viewerID uuid.UUID) []string {
+	seen, err := s.impressionCache.GetSeen(ctx, viewerID.String(), time.Now().Add(-cache.SeenRetention))
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Warn("failed to get seen profiles", zap.Error(err), zap.String("viewer_id", viewerID.String()))
+		return nil
+	}
+	return seen
+}
+
+// flush upserts the daily viewers of a batch of impressions
+func (s *ImpressionService) flush(ctx context.Context, impressions []cache.Impression) error {
+	viewers, err := s.impressionCache.CountDailyViewers(ctx, impressions)
+	if err != nil {
+		s.logError(ctx, err, "failed to count daily viewers")
+		return err
+	}
+
+	rows := make([]model.ProfileImpression, 0, len(impressions))
+	for i, impression := range impressions {
+		userID, err := uuid.Parse(impression.UserID)
+		if err != nil {
+			continue
+		}
+		rows = append(rows, model.ProfileImpression{UserID: userID, Day: impression.Day, Viewers: viewers[i]})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	err = s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]any{"viewers": clause.Expr{SQL: "GREATEST(profile_impressions.viewers, EXCLUDED.viewers)"}, "updated_at": time.Now()}),
+	}).Create(&rows).Error
+	if err != nil {
+		s.logError(ctx, err, "failed to flush impressions", zap.Int("count", len(rows)))
+	}
+	return err
+}
+
+func (s *ImpressionService) logError(ctx context.Context, err error, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Error(msg, append(fields, zap.Error(err))...)
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"konnect/internal/cache"
+	"konnect/internal/database"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/util"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInterestNotFound    = errors.New("interest not found")
+	ErrInterestExists      = errors.New("interest with this name or slug already exists")
+	ErrInterestMergeSelf   = errors.New("interest cannot be merged into itself")
+	ErrInterestMergeTarget = errors.New("interests can only be merged into an active interest")
+)
+
+// interestCatalogTTL is how long a replica serves the in-memory catalog before reloading it, so admin changes made on
+// other replicas show up within this delay
+const interestCatalogTTL = time.Minute
+
+type InterestService struct {
+	db            *database.DB
+	interestCache *cache.InterestCache
+	logger        *zap.Logger
+
+	mu       sync.RWMutex
+	catalog  []model.Interest
+	active   map[string]struct{}
+	loadedAt time.Time
+}
+
+func NewInterestService(db *database.DB, interestCache *cache.InterestCache, logger *logger.Logger) *InterestService {
+	return &InterestService{
+		db:            db,
+		interestCache: interestCache,
+		logger:        logger.With(zap.String("component", "interest_service")),
+	}
+}
+
+// GetCatalog returns the active interests grouped by category with labels in the requested locale
+func (s *InterestService) GetCatalog(ctx context.Context, locale string) ([]model.InterestCategoryResponse, error) {
+	catalog, _, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := make(map[model.InterestCategory][]model.InterestResponse)
+	for _, interest := range catalog {
+		grouped[interest.Category] = append(grouped[interest.Category], interestResponse(interest, locale))
+	}
+
+	response := make([]model.InterestCategoryResponse, 0, len(grouped))
+	for _, category := range model.InterestCategories {
+		if interests, ok := grouped[category]; ok {
+			response = append(response, model.InterestCategoryResponse{Category: category, Interests: interests})
+		}
+	}
+	return response, nil
+}
+
+// ValidateInterests reports whether every interest is an active interest of the catalog
+func (s *InterestService) ValidateInterests(ctx context.Context, interests []string) (bool, error) {
+	_, active, err := s.load(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, interest := range interests {
+		if _, ok := active[interest]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// GetAllInterests returns the whole catalog including retired interests
+func (s *InterestService) GetAllInterests(ctx context.Context) ([]model.Interest, error) {
+	var interests []model.Interest
+	if err := s.db.WithContext(ctx).Order("category, name").Find(&interests).Error; err != nil {
+		s.logError(ctx, err, "failed to get interests")
+		return nil, err
+	}
+	return interests, nil
+}
+
+// CreateInterest adds an interest to the catalog. The slug is derived from the name when empty
+func (s *InterestService) CreateInterest(ctx context.Context, req model.CreateInterestRequest) (*model.Interest, error) {
+	slug := req.Slug
+	if slug == "" {
+		slug = util.Slugify(req.Name)
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Unscoped().Model(&model.Interest{}).Where("name = ? OR slug = ?", req.Name, slug).Count(&count).Error; err != nil {
+		s.logError(ctx, err, "failed to check interest", zap.String("name", req.Name))
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrInterestExists
+	}
+
+	interest := &model.Interest{
+		Slug:     slug,
+		Name:     req.Name,
+		Category: req.Category,
+		Active:   true,
+		Labels:   req.Labels,
+	}
+	if err := s.db.WithContext(ctx).Create(interest).Error; err != nil {
+		s.logError(ctx, err, "failed to create interest", zap.String("name", req.Name))
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrInterestExists
+		}
+		return nil, err
+	}
+
+	s.invalidate()
+	return interest, nil
+}
+
+// UpdateInterest changes the category, labels or active flag of an interest. Retiring an interest keeps it on existing profiles
+func (s *InterestService) UpdateInterest(ctx context.Context, id uuid.UUID, req model.UpdateInterestRequest) (*model.Interest, error) {
+	interest, err := s.getInterest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]any)
+	if req.Category != nil {
+		updates["category"] = *req.Category
+	}
+	if req.Labels != nil {
+		updates["labels"] = req.Labels
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+	if len(updates) == 0 {
+		return interest, nil
+	}
+
+	if err := s.db.WithContext(ctx).Model(interest).Updates(updates).Error; err != nil {
+		s.logError(ctx, err, "failed to update interest", zap.String("id", id.String()))
+		return nil, err
+	}
+
+	s.invalidate()
+	return s.getInterest(ctx, id)
+}
+
+// RetireInterest hides an interest from the catalog so it cannot be picked anymore
+func (s *InterestService) RetireInterest(ctx context.Context, id uuid.UUID) (*model.Interest, error) {
+	active := false
+	return s.UpdateInterest(ctx, id, model.UpdateInterestRequest{Active: &active})
+}
+
+// MergeInterest replaces the source interest with the target on every profile, moves the redis bucket members and retires the source
+func (s *InterestService) MergeInterest(ctx context.Context, sourceID, targetID uuid.UUID) (*model.MergeInterestResponse, error) {
+	if sourceID == targetID {
+		return nil, ErrInterestMergeSelf
+	}
+	source, err := s.getInterest(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.getInterest(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if !target.Active {
+		return nil, ErrInterestMergeTarget
+	}
+
+	// rename the interest in place keeping the order of the remaining interests and dropping duplicates
+	var migrated int64
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Exec(`
+			UPDATE profiles SET interests = (
+				SELECT jsonb_agg(name ORDER BY position) FROM (
+					SELECT CASE WHEN value = @source THEN @target ELSE value END AS name, MIN(ordinality) AS position
+					FROM jsonb_array_elements_text(profiles.interests) WITH ORDINALITY
+					GROUP BY 1
+				) merged
+			), updated_at = NOW()
+			WHERE interests @> jsonb_build_array(@source::text)
+		`, map[string]any{"source": source.Name, "target": target.Name})
+		if res.Error != nil {
+			return res.Error
+		}
+		migrated = res.RowsAffected
+
+		return tx.Model(source).Updates(map[string]any{"active": false, "merged_into_id": target.ID}).Error
+	})
+	if err != nil {
+		s.logError(ctx, err, "failed to merge interest", zap.String("source", source.Name), zap.String("target", target.Name))
+		return nil, err
+	}
+	s.invalidate()
+
+	// postgres is the source of truth, the cache seeding repairs the buckets if this fails
+	if err := s.interestCache.MergeInterest(ctx, source.Name, target.Name); err != nil {
+		logger.FromContext(ctx, s.logger).Warn("failed to merge interest buckets", zap.Error(err), zap.String("source", source.Name), zap.String("target", target.Name))
+	}
+
+	return &model.MergeInterestResponse{Target: *target, ProfilesMigrated: migrated}, nil
+}
+
+// GetStats returns the number of members of each active interest, most popular first. Counts come from the interest cache
+// unless coordinates are given, in which case only profiles within the radius are counted
+func (s *InterestService) GetStats(ctx context.Context, query model.InterestStatsQuery) ([]model.InterestStat, error) {
+	catalog, _, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var counts map[string]int64
+	if query.Lat != nil && query.Lng != nil {
+		counts, err = s.countNearbyMembers(ctx, *query.Lat, *query.Lng, query.Radius)
+	} else {
+		names := make([]string, 0, len(catalog))
+		for _, interest := range catalog {
+			names = append(names, interest.Name)
+		}
+		counts, err = s.interestCache.GetInterestMembersCounts(ctx, names)
+	}
+	if err != nil {
+		s.logError(ctx, err, "failed to count interest members")
+		return nil, err
+	}
+
+	stats := make([]model.InterestStat, 0, len(catalog))
+	for _, interest := range catalog {
+		stats = append(stats, model.InterestStat{
+			InterestResponse: interestResponse(interest, query.Locale),
+			Category:         interest.Category,
+			Members:          counts[interest.Name],
+		})
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Members > stats[j].Members })
+	return stats, nil
+}
+
+// GetTrending returns the active interests added the most over the last days
+func (s *InterestService) GetTrending(ctx context.Context, query model.TrendingInterestsQuery) ([]model.TrendingInterest, error) {
+	catalog, _, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]model.Interest, len(catalog))
+	for _, interest := range catalog {
+		byName[interest.Name] = interest
+	}
+
+	scores, err := s.interestCache.GetTrendingInterests(ctx, query.Days)
+	if err != nil {
+		s.logError(ctx, err, "failed to get trending interests")
+		return nil, err
+	}
+
+	// scores are sorted, retired interests are skipped
+	trending := make([]model.TrendingInterest, 0, query.Limit)
+	for _, score := range scores {
+		interest, ok := byName[score.Member.(string)]
+		if !ok {
+			continue
+		}
+		trending = append(trending, model.TrendingInterest{
+			InterestResponse: interestResponse(interest, query.Locale),
+			Category:         interest.Category,
+			Additions:        int64(score.Score),
+		})
+		if len(trending) == query.Limit {
+			break
+		}
+	}
+	return trending, nil
+}
+
+// countNearbyMembers counts the profiles having each interest within radius meters of the coordinates
+func (s *InterestService) countNearbyMembers(ctx context.Context, lat, lng, radiusMeters float64) (map[string]int64, error) {
+	var rows []struct {
+		Name    string
+		Members int64
+	}
+	// (lon, lat)
+	err := s.db.WithContext(ctx).Raw(`
+		SELECT interest.value AS name, COUNT(*) AS members
+		FROM profiles, jsonb_array_elements_text(profiles.interests) AS interest
+		WHERE profiles.deleted_at IS NULL AND ST_DWithin(profiles.location, ST_Point(?, ?)::GEOGRAPHY, ?)
+		GROUP BY interest.value
+	`, lng, lat, radiusMeters).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Name] = row.Members
+	}
+	return counts, nil
+}
+
+func (s *InterestService) getInterest(ctx context.Context, id uuid.UUID) (*model.Interest, error) {
+	var interest model.Interest
+	if err := s.db.WithContext(ctx).Where("id = ?", id).Take(&interest).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInterestNotFound
+		}
+		return nil, err
+	}
+	return &interest, nil
+}
+
+// load returns the active catalog, reloading it from postgres once it is older than the catalog ttl
+func (s *InterestService) load(ctx context.Context) ([]model.Interest, map[string]struct{}, error) {
+	s.mu.RLock()
+	if s.catalog != nil && time.Since(s.loadedAt) < interestCatalogTTL {
+		defer s.mu.RUnlock()
+		return s.catalog, s.active, nil
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// another request may have reloaded it meanwhile
+	if s.catalog != nil && time.Since(s.loadedAt) < interestCatalogTTL {
+		return s.catalog, s.active, nil
+	}
+
+	var catalog []model.Interest
+	if err := s.db.WithContext(ctx).Where("active = ?", true).Order("name").Find(&catalog).Error; err != nil {
+		s.logError(ctx, err, "failed to load interest catalog")
+		return nil, nil, err
+	}
+
+	active := make(map[string]struct{}, len(catalog))
+	for _, interest := range catalog {
+		active[interest.Name] = struct{}{}
+	}
+
+	s.catalog, s.active, s.loadedAt = catalog, active, time.Now()
+	return catalog, active, nil
+}
+
+// invalidate drops the in-memory catalog after an admin change
+func (s *InterestService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.catalog, s.active = nil, nil
+}
+
+func interestResponse(interest model.Interest, locale string) model.InterestResponse {
+	return model.InterestResponse{
+		ID:    interest.ID,
+		Slug:  interest.Slug,
+		Name:  interest.Name,
+		Label: interest.Label(locale),
+	}
+}
+
+func (s *InterestService) logError(ctx context.Context, err error, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Error(msg, append(fields, zap.Error(err))...)
+}
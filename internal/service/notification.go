@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"konnect/internal/database"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+type NotificationService struct {
+	db     *database.DB
+	logger *zap.Logger
+}
+
+func NewNotificationService(db *database.DB, logger *logger.Logger) *NotificationService {
+	return &NotificationService{
+		db:     db,
+		logger: logger.With(zap.String("component", "notification_service")),
+	}
+}
+
+// Send persists an in-app notification for the recipient. It implements the worker.InAppDispatcher interface
+func (s *NotificationService) Send(ctx context.Context, payload model.InAppPayload) error {
+	notification := &model.Notification{
+		UserID: payload.UserID,
+		Type:   payload.Type,
+		Title:  payload.Title,
+		Body:   payload.Body,
+		Data:   payload.Data,
+	}
+
+	if err := s.db.WithContext(ctx).Create(notification).Error; err != nil {
+		s.logError(ctx, err, "failed to create notification",
+			zap.String("user_id", payload.UserID.String()),
+			zap.String("type", string(payload.Type)),
+		)
+		return err
+	}
+	return nil
+}
+
+// GetNotifications retrieves the most recent notifications of a user
+func (s *NotificationService) GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]model.Notification, error) {
+	var notifications []model.Notification
+
+	query := s.db.WithContext(ctx).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&notifications).Error; err != nil {
+		s.logError(ctx, err, "failed to get notifications", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// CountUnread returns the number of notifications the user has not read yet
+func (s *NotificationService) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		s.logError(ctx, err, "failed to count unread notifications", zap.String("user_id", userID.String()))
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkAsRead marks a single notification owned by the user as read. Already read notifications keep their original read time
+func (s *NotificationService) MarkAsRead(ctx context.Context, userID, id uuid.UUID) (*model.Notification, error) {
+	var notification model.Notification
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Take(&notification).Error; err != nil {
+		return nil, ErrNotificationNotFound
+	}
+	if notification.ReadAt != nil {
+		return &notification, nil
+	}
+
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(&notification).Update("read_at", now).Error; err != nil {
+		s.logError(ctx, err, "failed to mark notification as read", zap.String("id", id.String()))
+		return nil, err
+	}
+	notification.ReadAt = &now
+	return &notification, nil
+}
+
+// MarkAllAsRead marks every unread notification of the user as read and returns the number of updated notifications
+func (s *NotificationService) MarkAllAsRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	res := s.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	if res.Error != nil {
+		s.logError(ctx, res.Error, "failed to mark all notifications as read", zap.String("user_id", userID.String()))
+		return 0, res.Error
+	}
+	return res.RowsAffected, nil
+}
+
+func (s *NotificationService) logError(ctx context.Context, err error, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Error(msg, append(fields, zap.Error(err))...)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"konnect/internal/config"
+	"konnect/internal/logger"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// OutboxEmail is an email captured by the outbox transport
+type OutboxEmail struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+	// sent in the List-Unsubscribe header, empty for transactional emails
+	UnsubscribeURL string
+	SentAt         time.Time
+}
+
+// OutboxEmailService keeps emails in memory instead of sending them so tests can assert on them.
+// When a directory is configured every email is also written there as an .eml file for inspection across processes
+type OutboxEmailService struct {
+	mu       sync.Mutex
+	messages []OutboxEmail
+	from     *mail.Address
+	dir      string
+	logger   *zap.Logger
+}
+
+func NewOutboxEmailService(cfg *config.Config, logger *logger.Logger) (*OutboxEmailService, error) {
+	from, err := mail.ParseAddress(cfg.EmailFrom)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	if cfg.EmailOutboxDir != "" {
+		if err := os.MkdirAll(cfg.EmailOutboxDir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create email outbox directory: %w", err)
+		}
+	}
+
+	return &OutboxEmailService{
+		from:   from,
+		dir:    cfg.EmailOutboxDir,
+		logger: logger.With(zap.String("component", "outbox_email_service")),
+	}, nil
+}
+
+func (s *OutboxEmailService) Send(ctx context.Context, email string, subject string, html string, text string, unsubscribeURL string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	message := OutboxEmail{To: email, Subject: subject, HTML: html, Text: text, UnsubscribeURL: unsubscribeURL, SentAt: time.Now()}
+	s.messages = append(s.messages, message)
+
+	if s.dir != "" {
+		msg, err := buildMIMEMessage(s.from, email, subject, html, text, unsubscribeURL)
+		if err != nil {
+			return err
+		}
+
+		// e.g. 1700000000000000000-ama@konnect.app.eml
+		filename := fmt.Sprintf("%d-%s.eml", message.SentAt.UnixNano(), strings.NewReplacer("/", "_", "\\", "_").Replace(email))
+		if err := os.WriteFile(filepath.Join(s.dir, filename), msg, 0o644); err != nil {
+			return fmt.Errorf("failed to write email to outbox: %w", err)
+		}
+	}
+
+	logger.FromContext(ctx, s.logger).Info("email captured in outbox", zap.String("subject", subject))
+	return nil
+}
+
+// Messages returns a copy of all captured emails in the order they were sent
+func (s *OutboxEmailService) Messages() []OutboxEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]OutboxEmail(nil), s.messages...)
+}
+
+// MessagesTo returns the captured emails sent to an address
+func (s *OutboxEmailService) MessagesTo(email string) []OutboxEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var messages []OutboxEmail
+	for _, message := range s.messages {
+		if strings.EqualFold(message.To, email) {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// Reset clears the captured emails. Files already written to the outbox directory are kept
+func (s *OutboxEmailService) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = nil
+}
@@ -1,10 +1,22 @@
 package service
 
 import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"konnect/internal/config"
 	"konnect/internal/logger"
-
+	"konnect/internal/model"
+	"konnect/internal/util"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/go-resty/resty/v2"
@@ -17,16 +29,37 @@ type PaystackService struct {
 }
 
 var (
+	// paystack could not be reached or failed to handle the request, retrying later may succeed
 	ErrPaystackServer = errors.New("paystack server error")
+	// paystack rejected the request, e.g. an invalid otp
 	ErrPaystackClient = errors.New("paystack client error")
+	// a charge or transaction with the reference exists already, e.g. when the response to the request creating it was
+	// lost. It is a client error
+	ErrPaystackDuplicateReference = fmt.Errorf("%w: duplicate transaction reference", ErrPaystackClient)
+	// mobile money is only available on ghanaian networks
+	ErrUnknownMobileNetwork = errors.New("unknown service provider")
+)
+
+const (
+	paystackTimeout = 15 * time.Second
+	// retries of reads and referenced requests that failed, and of requests that were rate limited
+	paystackRetries      = 3
+	paystackRetryWait    = 500 * time.Millisecond
+	paystackRetryMaxWait = 5 * time.Second
 )
 
 // NewPaystackService creates a new instance of the Paystack service with a base http client.
 // The http client must be closed on shutdown
 func NewPaystackService(cfg *config.Config, logger *logger.Logger) *PaystackService {
-	// base client with auth header
-	httpClient := resty.New().SetBaseURL("https://api.paystack.co/")
-	// httpClient.SetHeader("Authorization", "Bearer "+cfg.PaystackSecret)
+	// base client with auth header. Failed requests are retried with backoff when retrying is safe
+	httpClient := resty.New().
+		SetBaseURL(cfg.PaystackBaseURL).
+		SetHeader("Authorization", "Bearer "+cfg.PaystackSecret).
+		SetTimeout(paystackTimeout).
+		SetRetryCount(paystackRetries).
+		SetRetryWaitTime(paystackRetryWait).
+		SetRetryMaxWaitTime(paystackRetryMaxWait).
+		AddRetryCondition(retryPaystackRequest)
 
 	return &PaystackService{
 		cfg:        cfg,
@@ -39,176 +72,240 @@ func (s *PaystackService) logPaystackError(err error, msg string, fields ...zap.
 	s.logger.Error(msg, append(fields, zap.String("component", "paystack_service"), zap.Error(err))...)
 }
 
-// func (s *PaystackService) InitiateMobileMoneyCharge(amount int64, email string, phone string, provider model.PaystackProviderCode) (*model.PaystackCreateChargeResponse, error) {
-// 	reference := s.GenerateReference()
-// 	body := model.PaystackCreateCharge{
-// 		Amount:    int(100 * amount),
-// 		Email:     email,
-// 		Reference: &reference,
-// 		Currency:  "GHS",
-// 	}
-// 	body.MobileMoney.Phone = phone
-// 	body.MobileMoney.Provider = provider
-
-// 	var (
-// 		resp    model.PaystackCreateChargeResponse
-// 		errResp model.PaystackErrorResponse
-// 	)
-
-// 	res, err := s.httpClient.R().
-// 		SetBody(body).
-// 		SetResult(&resp).
-// 		SetError(&errResp).
-// 		Post("charge")
-
-// 	if err != nil {
-// 		s.logPaystackError(err, "http request failed for mobile money charge",
-// 			zap.String("email", email),
-// 			zap.String("phone", phone),
-// 			zap.Int64("amount", amount),
-// 			zap.String("provider", string(provider)),
-// 			zap.String("reference", reference),
-// 		)
-// 		return nil, fmt.Errorf("%w: %v", ErrPaystackClient, err)
-// 	}
-// 	if res.IsError() {
-// 		return nil, fmt.Errorf("%v", errResp.Message)
-// 	}
-
-// 	return &resp, nil
-// }
-
-// func (s *PaystackService) SubmitOTP(reference string, otp string) (*model.PaystackSubmitOtpResponse, error) {
-// 	body := map[string]string{
-// 		"otp":       otp,
-// 		"reference": reference,
-// 	}
-
-// 	var (
-// 		resp    model.PaystackSubmitOtpResponse
-// 		errResp model.PaystackErrorResponse
-// 	)
-
-// 	res, err := s.httpClient.R().
-// 		SetBody(body).
-// 		SetResult(&resp).
-// 		SetError(&errResp).
-// 		Post("charge/submit_otp")
-
-// 	if err != nil {
-// 		s.logPaystackError(err, "http request failed for OTP submission", zap.String("reference", reference))
-// 		return nil, fmt.Errorf("%w: %v", ErrPaystackServer, err)
-// 	}
-
-// 	if res.IsError() {
-// 		return nil, fmt.Errorf("%v", errResp.Message)
-// 	}
-
-// 	return &resp, nil
-// }
-
-// func (s *PaystackService) InitiateTransaction(amount int64, email string) (*model.PaystackInitTransferResponse, error) {
-// 	reference := s.GenerateReference()
-// 	body := model.PaystackInitTransfer{
-// 		Amount:    strconv.FormatInt(100*amount, 10),
-// 		Email:     email,
-// 		Reference: &reference,
-// 	}
-
-// 	var (
-// 		resp    model.PaystackInitTransferResponse
-// 		errResp model.PaystackErrorResponse
-// 	)
-
-// 	res, err := s.httpClient.R().
-// 		SetBody(body).
-// 		SetResult(&resp).
-// 		SetError(&errResp).
-// 		Post("transaction/initialize")
-
-// 	if err != nil {
-// 		s.logPaystackError(err, "http request failed for transaction initialization",
-// 			zap.String("email", email),
-// 			zap.Int64("amount", amount),
-// 			zap.String("reference", reference),
-// 		)
-// 		return nil, fmt.Errorf("%w: %v", ErrPaystackServer, err)
-// 	}
-// 	if res.IsError() {
-// 		return nil, fmt.Errorf("%v", errResp.Message)
-// 	}
-
-// 	return &resp, nil
-// }
-
-// func (s *PaystackService) VerifyTransaction(reference string) (*model.PaystackVerifyResponse, error) {
-// 	var (
-// 		resp    model.PaystackVerifyResponse
-// 		errResp model.PaystackErrorResponse
-// 	)
-
-// 	res, err := s.httpClient.R().
-// 		SetResult(&resp).
-// 		SetError(&errResp).
-// 		Get("transaction/verify/" + reference)
-
-// 	if err != nil {
-// 		s.logPaystackError(err, "http request failed for transaction verification", zap.String("reference", reference))
-// 		return nil, fmt.Errorf("%w: %v", ErrPaystackServer, err)
-// 	}
-// 	if res.IsError() {
-// 		return nil, fmt.Errorf("%v", errResp.Message)
-// 	}
-
-// 	return &resp, nil
-// }
-
-// // VerifyWebhookSignature validates the payload hash against the provided signature from the webhook headers
-// func (s *PaystackService) VerifyWebhookSignature(payload []byte, signature string) bool {
-// 	// hash of payload with paystack secret must match signature
-// 	mac := hmac.New(sha512.New, []byte(s.cfg.PaystackSecret))
-// 	mac.Write(payload)
-// 	expectedMAC := mac.Sum(nil)
-// 	expectedSignature := hex.EncodeToString(expectedMAC)
-
-// 	return hmac.Equal([]byte(signature), []byte(expectedSignature))
-// }
-
-// // IsValidOrigin checks if the provided source IP matches paystack's provided IPs
-// func (s *PaystackService) IsValidOrigin(ip string) bool {
-// 	return slices.Contains(s.cfg.PaystackOrigins, ip)
-// }
-
-// // GenerateReference generates a random reference for the transaction
-// func (s *PaystackService) GenerateReference() string {
-// 	return "charge_" + strings.ReplaceAll(uuid.New().String(), "-", "")
-// }
-
-// // GetProvider retrieves the provider based on the phone number prefix
-// func (s *PaystackService) GetProvider(phone string) (model.PaystackProviderCode, error) {
-// 	// remove space and leading zero or 233
-// 	phone = strings.TrimSpace(phone)
-// 	phone = strings.ReplaceAll(phone, " ", "")
-// 	phone = strings.ReplaceAll(phone, "+", "")
-
-// 	if strings.HasPrefix(phone, "233") {
-// 		phone = strings.TrimPrefix(phone, "233")
-// 	} else if strings.HasPrefix(phone, "0") {
-// 		phone = strings.TrimPrefix(phone, "0")
-// 	}
-
-// 	if len(phone) < 2 {
-// 		return "", errors.New("invalid phone number")
-// 	}
-
-// 	switch phone[:2] {
-// 	case "24", "54", "55", "59", "25":
-// 		return model.MTN, nil
-// 	case "20", "50":
-// 		return model.Vodafone, nil
-// 	case "26", "27", "56":
-// 		return model.AirtelTigo, nil
-// 	default:
-// 		return "", errors.New("unknown service provider")
-// 	}
-// }
+// InitiateMobileMoneyCharge charges a mobile money wallet. The amount is in the minor unit of the currency. The charge is
+// usually pending until the customer submits an otp or approves it on their phone
+func (s *PaystackService) InitiateMobileMoneyCharge(reference string, amount int64, currency, email, phone string, provider model.PaystackProviderCode) (*model.PaystackCreateChargeResponse, error) {
+	body := model.PaystackCreateCharge{
+		Amount:    int(amount),
+		Email:     email,
+		Reference: &reference,
+		Currency:  currency,
+	}
+	body.MobileMoney.Phone = phone
+	body.MobileMoney.Provider = provider
+
+	var (
+		resp    model.PaystackCreateChargeResponse
+		errResp model.PaystackErrorResponse
+	)
+
+	res, err := s.httpClient.R().
+		SetBody(body).
+		SetResult(&resp).
+		SetError(&errResp).
+		AddRetryCondition(retryReferencedRequest).
+		Post("charge")
+
+	if err != nil {
+		s.logPaystackError(err, "http request failed for mobile money charge",
+			zap.String("email", email),
+			zap.String("phone", phone),
+			zap.Int64("amount", amount),
+			zap.String("provider", string(provider)),
+			zap.String("reference", reference),
+		)
+		return nil, fmt.Errorf("%w: %v", ErrPaystackServer, err)
+	}
+	if res.IsError() {
+		return nil, responseError(res, &errResp)
+	}
+
+	return &resp, nil
+}
+
+// SubmitOTP completes a pending charge with the otp the customer received
+func (s *PaystackService) SubmitOTP(reference string, otp string) (*model.PaystackSubmitOtpResponse, error) {
+	body := map[string]string{
+		"otp":       otp,
+		"reference": reference,
+	}
+
+	var (
+		resp    model.PaystackSubmitOtpResponse
+		errResp model.PaystackErrorResponse
+	)
+
+	res, err := s.httpClient.R().
+		SetBody(body).
+		SetResult(&resp).
+		SetError(&errResp).
+		Post("charge/submit_otp")
+
+	if err != nil {
+		s.logPaystackError(err, "http request failed for OTP submission", zap.String("reference", reference))
+		return nil, fmt.Errorf("%w: %v", ErrPaystackServer, err)
+	}
+
+	if res.IsError() {
+		return nil, responseError(res, &errResp)
+	}
+
+	return &resp, nil
+}
+
+// InitiateTransaction creates a transaction paid on the paystack checkout page. The amount is in the minor unit of the currency.
+// A paystack plan code subscribes the customer to the plan, which paystack then renews on its own
+func (s *PaystackService) InitiateTransaction(reference string, amount int64, currency, email, planCode string, metadata map[string]any) (*model.PaystackInitTransferResponse, error) {
+	body := model.PaystackInitTransfer{
+		Amount:      strconv.FormatInt(amount, 10),
+		Email:       email,
+		Currency:    currency,
+		Reference:   &reference,
+		CallbackURL: s.cfg.PaystackReturnURL,
+		Plan:        planCode,
+		Metadata:    metadata,
+	}
+
+	var (
+		resp    model.PaystackInitTransferResponse
+		errResp model.PaystackErrorResponse
+	)
+
+	res, err := s.httpClient.R().
+		SetBody(body).
+		SetResult(&resp).
+		SetError(&errResp).
+		AddRetryCondition(retryReferencedRequest).
+		Post("transaction/initialize")
+
+	if err != nil {
+		s.logPaystackError(err, "http request failed for transaction initialization",
+			zap.String("email", email),
+			zap.Int64("amount", amount),
+			zap.String("reference", reference),
+		)
+		return nil, fmt.Errorf("%w: %v", ErrPaystackServer, err)
+	}
+	if res.IsError() {
+		return nil, responseError(res, &errResp)
+	}
+
+	return &resp, nil
+}
+
+// VerifyTransaction fetches the current state of a transaction
+func (s *PaystackService) VerifyTransaction(reference string) (*model.PaystackVerifyResponse, error) {
+	var (
+		resp    model.PaystackVerifyResponse
+		errResp model.PaystackErrorResponse
+	)
+
+	res, err := s.httpClient.R().
+		SetResult(&resp).
+		SetError(&errResp).
+		Get("transaction/verify/" + reference)
+
+	if err != nil {
+		s.logPaystackError(err, "http request failed for transaction verification", zap.String("reference", reference))
+		return nil, fmt.Errorf("%w: %v", ErrPaystackServer, err)
+	}
+	if res.IsError() {
+		return nil, responseError(res, &errResp)
+	}
+
+	return &resp, nil
+}
+
+// retryPaystackRequest reports whether a failed request can be sent again. Rate limited requests were not handled and
+// are always retried. Reads are retried when they time out or fail on the paystack side
+func retryPaystackRequest(res *resty.Response, err error) bool {
+	if res == nil {
+		return false
+	}
+	if res.StatusCode() == http.StatusTooManyRequests {
+		return true
+	}
+	if res.Request == nil || res.Request.Method != http.MethodGet {
+		return false
+	}
+	return err != nil || res.StatusCode() >= http.StatusInternalServerError
+}
+
+// retryReferencedRequest is the retry condition of requests creating a charge or transaction under a reference. They may
+// have been handled when they time out or fail on the paystack side. The retry reuses the reference, so paystack refuses
+// it with ErrPaystackDuplicateReference instead of charging twice, and the payment is settled by verification
+func retryReferencedRequest(res *resty.Response, err error) bool {
+	if res == nil {
+		return false
+	}
+	return err != nil || res.StatusCode() >= http.StatusInternalServerError
+}
+
+// responseError maps an error response of paystack to ErrPaystackServer or ErrPaystackClient, keeping its message
+func responseError(res *resty.Response, errResp *model.PaystackErrorResponse) error {
+	message := errResp.Message
+	if message == "" {
+		message = res.Status()
+	}
+	if res.StatusCode() == http.StatusTooManyRequests || res.StatusCode() >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: %s", ErrPaystackServer, message)
+	}
+	if strings.Contains(strings.ToLower(message), "duplicate transaction reference") {
+		return fmt.Errorf("%w: %s", ErrPaystackDuplicateReference, message)
+	}
+	return fmt.Errorf("%w: %s", ErrPaystackClient, message)
+}
+
+// VerifyWebhookSignature validates the payload hash against the provided signature from the webhook headers. Without a
+// secret key anyone could sign events, so every event is rejected
+func (s *PaystackService) VerifyWebhookSignature(payload []byte, signature string) bool {
+	if s.cfg.PaystackSecret == "" {
+		return false
+	}
+
+	// hash of payload with paystack secret must match signature
+	mac := hmac.New(sha512.New, []byte(s.cfg.PaystackSecret))
+	mac.Write(payload)
+	expectedMAC := mac.Sum(nil)
+	expectedSignature := hex.EncodeToString(expectedMAC)
+
+	return hmac.Equal([]byte(signature), []byte(expectedSignature))
+}
+
+// IsValidOrigin checks if the provided source IP matches paystack's provided IPs
+func (s *PaystackService) IsValidOrigin(ip string) bool {
+	return slices.Contains(s.cfg.PaystackOrigins, ip)
+}
+
+// GenerateReference generates a random reference for the transaction
+func (s *PaystackService) GenerateReference() string {
+	return "charge_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+}
+
+// GetProvider retrieves the provider based on the phone number prefix
+func (s *PaystackService) GetProvider(phone string) (model.PaystackProviderCode, error) {
+	phone = nationalNumber(phone)
+	if len(phone) < 2 {
+		return "", util.ErrInvalidPhoneNumber
+	}
+
+	switch phone[:2] {
+	case "24", "54", "55", "59", "25":
+		return model.MTN, nil
+	case "20", "50":
+		return model.Vodafone, nil
+	case "26", "27", "56":
+		return model.AirtelTigo, nil
+	default:
+		return "", ErrUnknownMobileNetwork
+	}
+}
+
+// LocalPhoneNumber formats a ghanaian phone number the way mobile money charges expect it, e.g. 0241234567
+func (s *PaystackService) LocalPhoneNumber(phone string) string {
+	return "0" + nationalNumber(phone)
+}
+
+// nationalNumber removes formatting and the leading zero or 233 from a ghanaian phone number
+func nationalNumber(phone string) string {
+	phone = strings.TrimSpace(phone)
+	phone = strings.ReplaceAll(phone, " ", "")
+	phone = strings.ReplaceAll(phone, "+", "")
+
+	if strings.HasPrefix(phone, "233") {
+		phone = strings.TrimPrefix(phone, "233")
+	} else if strings.HasPrefix(phone, "0") {
+		phone = strings.TrimPrefix(phone, "0")
+	}
+	return phone
+}
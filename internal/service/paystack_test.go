@@ -0,0 +1,280 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"konnect/internal/config"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/paystacktest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	testPaystackSecret = "sk_test_secret"
+	testPaystackOrigin = "127.0.0.1"
+)
+
+// newTestPaystack starts a fake paystack api and a client pointed at it. Retries wait briefly so tests stay fast
+func newTestPaystack(t *testing.T) (*PaystackService, *paystacktest.Server) {
+	t.Helper()
+
+	server := paystacktest.NewServer(testPaystackSecret)
+	t.Cleanup(server.Close)
+
+	paystack := NewPaystackService(&config.Config{
+		PaystackSecret:  testPaystackSecret,
+		PaystackBaseURL: server.BaseURL(),
+		PaystackOrigins: []string{testPaystackOrigin},
+	}, newTestLogger())
+	paystack.httpClient.SetRetryWaitTime(time.Millisecond).SetRetryMaxWaitTime(10 * time.Millisecond)
+
+	return paystack, server
+}
+
+func newTestLogger() *logger.Logger {
+	return &logger.Logger{Logger: zap.NewNop()}
+}
+
+func TestPaystackCheckoutAndVerify(t *testing.T) {
+	paystack, server := newTestPaystack(t)
+	reference := paystack.GenerateReference()
+
+	resp, err := paystack.InitiateTransaction(reference, 5000, "GHS", "ama@example.com", "", nil)
+	if err != nil {
+		t.Fatalf("InitiateTransaction() error = %v", err)
+	}
+	if resp.Data.Reference != reference || resp.Data.AuthorizationURL == "" {
+		t.Fatalf("InitiateTransaction() = %+v, want an authorization url for %s", resp.Data, reference)
+	}
+
+	verified, err := paystack.VerifyTransaction(reference)
+	if err != nil {
+		t.Fatalf("VerifyTransaction() error = %v", err)
+	}
+	if verified.Data.Status != model.PaystackOngoing {
+		t.Fatalf("status before payment = %s, want %s", verified.Data.Status, model.PaystackOngoing)
+	}
+
+	server.Complete(reference)
+	verified, err = paystack.VerifyTransaction(reference)
+	if err != nil {
+		t.Fatalf("VerifyTransaction() error = %v", err)
+	}
+	if verified.Data.Status != model.PaystackSuccess || verified.Data.Amount != 5000 || verified.Data.PaidAt == nil {
+		t.Fatalf("VerifyTransaction() = %+v, want a paid transaction of 5000", verified.Data)
+	}
+}
+
+func TestPaystackMobileMoneyOTP(t *testing.T) {
+	paystack, _ := newTestPaystack(t)
+	reference := paystack.GenerateReference()
+
+	charge, err := paystack.InitiateMobileMoneyCharge(reference, 5000, "GHS", "kofi@example.com", "0201234567", model.Vodafone)
+	if err != nil {
+		t.Fatalf("InitiateMobileMoneyCharge() error = %v", err)
+	}
+	if charge.Data.Status != model.PaystackSendOTP {
+		t.Fatalf("charge status = %s, want %s", charge.Data.Status, model.PaystackSendOTP)
+	}
+
+	if _, err := paystack.SubmitOTP(reference, "000000"); !errors.Is(err, ErrPaystackClient) {
+		t.Fatalf("SubmitOTP() with a wrong otp error = %v, want %v", err, ErrPaystackClient)
+	}
+
+	submitted, err := paystack.SubmitOTP(reference, paystacktest.OTP)
+	if err != nil {
+		t.Fatalf("SubmitOTP() error = %v", err)
+	}
+	if submitted.Data.Status != model.PaystackSuccess {
+		t.Fatalf("status after otp = %s, want %s", submitted.Data.Status, model.PaystackSuccess)
+	}
+}
+
+func TestPaystackMobileMoneyPolling(t *testing.T) {
+	paystack, server := newTestPaystack(t)
+	reference := paystack.GenerateReference()
+
+	charge, err := paystack.InitiateMobileMoneyCharge(reference, 5000, "GHS", "yaw@example.com", "0241234567", model.MTN)
+	if err != nil {
+		t.Fatalf("InitiateMobileMoneyCharge() error = %v", err)
+	}
+	if charge.Data.Status != model.PaystackPayOffline {
+		t.Fatalf("charge status = %s, want %s", charge.Data.Status, model.PaystackPayOffline)
+	}
+
+	// the customer approves the charge on their phone between two polls
+	statuses := make([]model.PaystackStatus, 0, 3)
+	for i := range 3 {
+		if i == 2 {
+			server.Complete(reference)
+		}
+		verified, err := paystack.VerifyTransaction(reference)
+		if err != nil {
+			t.Fatalf("VerifyTransaction() error = %v", err)
+		}
+		statuses = append(statuses, verified.Data.Status)
+	}
+	want := []model.PaystackStatus{model.PaystackPayOffline, model.PaystackPayOffline, model.PaystackSuccess}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Fatalf("polled statuses = %v, want %v", statuses, want)
+		}
+	}
+}
+
+func TestPaystackRetriesReads(t *testing.T) {
+	paystack, server := newTestPaystack(t)
+	reference := paystack.GenerateReference()
+	if _, err := paystack.InitiateTransaction(reference, 5000, "GHS", "esi@example.com", "", nil); err != nil {
+		t.Fatalf("InitiateTransaction() error = %v", err)
+	}
+
+	server.FailNext(paystackRetries)
+	if _, err := paystack.VerifyTransaction(reference); err != nil {
+		t.Fatalf("VerifyTransaction() after %d failures error = %v", paystackRetries, err)
+	}
+}
+
+func TestPaystackRetriesChargesWithTheirReference(t *testing.T) {
+	paystack, server := newTestPaystack(t)
+
+	server.FailNext(1)
+	reference := paystack.GenerateReference()
+	charge, err := paystack.InitiateMobileMoneyCharge(reference, 5000, "GHS", "abena@example.com", "0201234567", model.Vodafone)
+	if err != nil {
+		t.Fatalf("InitiateMobileMoneyCharge() after a server error error = %v", err)
+	}
+	if charge.Data.Reference != reference {
+		t.Fatalf("charge reference = %s, want %s", charge.Data.Reference, reference)
+	}
+	if _, ok := server.Transaction(reference); !ok {
+		t.Fatal("charge was not created")
+	}
+}
+
+func TestPaystackDoesNotRetrySubmittedOTPs(t *testing.T) {
+	paystack, server := newTestPaystack(t)
+	reference := paystack.GenerateReference()
+	if _, err := paystack.InitiateMobileMoneyCharge(reference, 5000, "GHS", "abena@example.com", "0201234567", model.Vodafone); err != nil {
+		t.Fatalf("InitiateMobileMoneyCharge() error = %v", err)
+	}
+
+	server.FailNext(1)
+	if _, err := paystack.SubmitOTP(reference, paystacktest.OTP); !errors.Is(err, ErrPaystackServer) {
+		t.Fatalf("SubmitOTP() error = %v, want %v", err, ErrPaystackServer)
+	}
+	if transaction, _ := server.Transaction(reference); transaction.Status == model.PaystackSuccess {
+		t.Fatal("otp was retried after a server error")
+	}
+}
+
+func TestPaystackDuplicateReference(t *testing.T) {
+	paystack, server := newTestPaystack(t)
+	reference := paystack.GenerateReference()
+
+	// paystack creates the charge but the response is lost, the retry with the same reference is refused
+	server.DropNext(1)
+	_, err := paystack.InitiateMobileMoneyCharge(reference, 5000, "GHS", "kwame@example.com", "0201234567", model.Vodafone)
+	if !errors.Is(err, ErrPaystackDuplicateReference) {
+		t.Fatalf("InitiateMobileMoneyCharge() error = %v, want %v", err, ErrPaystackDuplicateReference)
+	}
+	if _, ok := server.Transaction(reference); !ok {
+		t.Fatal("charge was not created")
+	}
+
+	// charging the reference again is refused as a duplicate, which is still a client error
+	_, err = paystack.InitiateMobileMoneyCharge(reference, 5000, "GHS", "kwame@example.com", "0201234567", model.Vodafone)
+	if !errors.Is(err, ErrPaystackDuplicateReference) || !errors.Is(err, ErrPaystackClient) {
+		t.Fatalf("InitiateMobileMoneyCharge() with a used reference error = %v, want %v", err, ErrPaystackDuplicateReference)
+	}
+}
+
+func TestReceivePaystackEventRejectsForgedEvents(t *testing.T) {
+	paystack, server := newTestPaystack(t)
+	reference := paystack.GenerateReference()
+	if _, err := paystack.InitiateTransaction(reference, 5000, "GHS", "efua@example.com", "", nil); err != nil {
+		t.Fatalf("InitiateTransaction() error = %v", err)
+	}
+	server.Complete(reference)
+	payload, signature, err := server.Webhook(model.PaystackChargeSuccess, reference)
+	if err != nil {
+		t.Fatalf("Webhook() error = %v", err)
+	}
+	if !paystack.VerifyWebhookSignature(payload, signature) {
+		t.Fatal("VerifyWebhookSignature() rejected a signed event")
+	}
+
+	// events are rejected before they are stored, so no database is needed
+	webhooks := NewWebhookService(nil, nil, paystack, nil, newTestLogger())
+	tampered := bytes.Replace(payload, []byte(`"amount":5000`), []byte(`"amount":500000`), 1)
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+		ip        string
+		want      error
+	}{
+		{name: "unknown origin", payload: payload, signature: signature, ip: "203.0.113.7", want: ErrInvalidWebhookOrigin},
+		{name: "missing signature", payload: payload, signature: "", ip: testPaystackOrigin, want: ErrInvalidWebhookSignature},
+		{name: "tampered body", payload: tampered, signature: signature, ip: testPaystackOrigin, want: ErrInvalidWebhookSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhooks.ReceivePaystackEvent(context.Background(), tt.payload, tt.signature, tt.ip)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ReceivePaystackEvent() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaystackEventIDIdentifiesRedeliveries(t *testing.T) {
+	paystack, server := newTestPaystack(t)
+	reference := paystack.GenerateReference()
+	if _, err := paystack.InitiateTransaction(reference, 5000, "GHS", "akua@example.com", "", nil); err != nil {
+		t.Fatalf("InitiateTransaction() error = %v", err)
+	}
+	server.Complete(reference)
+
+	eventID := func(event string) string {
+		payload, _, err := server.Webhook(event, reference)
+		if err != nil {
+			t.Fatalf("Webhook() error = %v", err)
+		}
+		var body model.PaystackEvent
+		if err := json.Unmarshal(payload, &body); err != nil {
+			t.Fatalf("invalid webhook payload: %v", err)
+		}
+		return paystackEventID(body, payload)
+	}
+
+	first, redelivery := eventID(model.PaystackChargeSuccess), eventID(model.PaystackChargeSuccess)
+	if first != redelivery {
+		t.Fatalf("redelivered event id = %s, want %s", redelivery, first)
+	}
+	if other := eventID("charge.dispute.create"); other == first {
+		t.Fatalf("event id of another event = %s, want it to differ", other)
+	}
+}
+
+func TestVerifyWebhookSignatureWithoutSecret(t *testing.T) {
+	paystack := NewPaystackService(&config.Config{}, newTestLogger())
+	payload := []byte(`{"event":"charge.success","data":{"id":1}}`)
+
+	// the signature anyone can compute with an empty key
+	mac := hmac.New(sha512.New, nil)
+	mac.Write(payload)
+	if paystack.VerifyWebhookSignature(payload, hex.EncodeToString(mac.Sum(nil))) {
+		t.Fatal("VerifyWebhookSignature() accepted an event without a secret key")
+	}
+}
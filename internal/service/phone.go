@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"konnect/internal/cache"
+	"konnect/internal/config"
+	"konnect/internal/database"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/template"
+	"konnect/internal/util"
+	"konnect/internal/worker"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrPhoneNotSet          = errors.New("phone number has not been set")
+	ErrPhoneAlreadyVerified = errors.New("phone number is already verified")
+	ErrPhoneChanged         = errors.New("phone number changed after the verification code was sent")
+)
+
+const otpDigits = 6
+
+type PhoneVerificationService struct {
+	db        *database.DB
+	cfg       *config.Config
+	otpCache  *cache.OTPCache
+	templates *template.Registry
+	worker    *asynq.Client
+	logger    *zap.Logger
+}
+
+func NewPhoneVerificationService(db *database.DB, cfg *config.Config, otpCache *cache.OTPCache, templates *template.Registry, worker *asynq.Client, logger *logger.Logger) *PhoneVerificationService {
+	return &PhoneVerificationService{
+		db:        db,
+		cfg:       cfg,
+		otpCache:  otpCache,
+		templates: templates,
+		worker:    worker,
+		logger:    logger.With(zap.String("component", "phone_verification_service")),
+	}
+}
+
+// SendOTP issues a new verification code for the user's phone number and delivers it by sms
+func (s *PhoneVerificationService) SendOTP(ctx context.Context, userID uuid.UUID) error {
+	var user model.User
+	if err := s.db.WithContext(ctx).Where("id = ?", userID).Take(&user).Error; err != nil {
+		return ErrUserNotFound
+	}
+	if user.PhoneNumber == nil {
+		return ErrPhoneNotSet
+	}
+	if user.PhoneVerified {
+		return ErrPhoneAlreadyVerified
+	}
+
+	code := util.GenerateNumericCode(otpDigits)
+	if err := s.otpCache.CreatePhoneOTP(ctx, userID.String(), *user.PhoneNumber, code, s.cfg.OTPExpiry, s.cfg.OTPResendCooldown); err != nil {
+		if !errors.Is(err, cache.ErrOTPCooldown) {
+			s.logError(ctx, err, "failed to store phone verification code", zap.String("user_id", userID.String()))
+		}
+		return err
+	}
+
+	message := fmt.Sprintf("Your Konnect verification code is %s. It expires in %d minutes.", code, int(s.cfg.OTPExpiry.Minutes()))
+	if err := worker.NewSMSDeliveryJob(s.worker, model.SMSPayload{
+		TaskMeta:     worker.NewTaskMeta(ctx),
+		PhoneNumbers: []string{*user.PhoneNumber},
+		Message:      message,
+	}); err != nil {
+		s.logError(ctx, err, "failed to enqueue phone verification sms", zap.String("user_id", userID.String()))
+		// the code was never sent, so the user can ask for another one straight away
+		if err := s.otpCache.DeletePhoneOTP(ctx, userID.String()); err != nil {
+			s.logError(ctx, err, "failed to discard unsent phone verification code", zap.String("user_id", userID.String()))
+		}
+		return err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("phone verification code sent", zap.String("user_id", userID.String()))
+	return nil
+}
+
+// OTPCooldown returns how long the user has to wait for a new verification code
+func (s *PhoneVerificationService) OTPCooldown(ctx context.Context, userID uuid.UUID) time.Duration {
+	cooldown, err := s.otpCache.PhoneOTPCooldown(ctx, userID.String())
+	if err != nil {
+		s.logError(ctx, err, "failed to get phone verification cooldown", zap.String("user_id", userID.String()))
+		return s.cfg.OTPResendCooldown
+	}
+	return cooldown
+}
+
+// VerifyOTP checks the verification code and marks the user's phone number as verified
+func (s *PhoneVerificationService) VerifyOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	phone, err := s.otpCache.VerifyPhoneOTP(ctx, userID.String(), code, s.cfg.OTPMaxAttempts)
+	if err != nil {
+		return err
+	}
+
+	// the code is only valid for the number it was sent to
+	res := s.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND phone_number = ?", userID, phone).
+		Update("phone_verified", true)
+	if res.Error != nil {
+		s.logError(ctx, res.Error, "failed to mark phone number as verified", zap.String("user_id", userID.String()))
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrPhoneChanged
+	}
+
+	logger.FromContext(ctx, s.logger).Info("phone number verified", zap.String("user_id", userID.String()))
+	s.notifyVerified(ctx, userID)
+	return nil
+}
+
+// notifyVerified tells the user their phone number is verified by email, in-app and by push. In-app and push
+// notifications reuse the subject and text of the email in the user's locale. The verification stands when the
+// notifications cannot be enqueued
+func (s *PhoneVerificationService) notifyVerified(ctx context.Context, userID uuid.UUID) {
+	var user model.User
+	if err := s.db.WithContext(ctx).Select("id", "email", "username", "locale").Where("id = ?", userID).Take(&user).Error; err != nil {
+		s.logError(ctx, err, "failed to get verified user", zap.String("user_id", userID.String()))
+		return
+	}
+
+	data := map[string]any{"username": user.Username}
+	message, err := s.templates.Render(string(model.VerificationNotification), user.Locale, data)
+	if err != nil {
+		s.logError(ctx, err, "failed to render verification notification", zap.String("user_id", userID.String()))
+		return
+	}
+
+	meta := worker.NewTaskMeta(ctx)
+	err = errors.Join(
+		worker.NewEmailDeliveryJob(s.worker, model.EmailPayload{
+			TaskMeta:   meta,
+			Email:      user.Email,
+			UserID:     user.ID,
+			Type:       model.VerificationNotification,
+			TemplateID: string(model.VerificationNotification),
+			Locale:     user.Locale,
+			Data:       data,
+		}),
+		worker.NewInAppDeliveryJob(s.worker, model.InAppPayload{TaskMeta: meta, UserID: user.ID, Type: model.VerificationNotification, Title: message.Subject, Body: message.Text}),
+		worker.NewPushDeliveryJob(s.worker, model.PushPayload{TaskMeta: meta, UserID: user.ID, Type: model.VerificationNotification, Title: message.Subject, Body: message.Text}),
+	)
+	if err != nil {
+		s.logError(ctx, err, "failed to send verification notification", zap.String("user_id", userID.String()))
+	}
+}
+
+func (s *PhoneVerificationService) logError(ctx context.Context, err error, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Error(msg, append(fields, zap.Error(err))...)
+}
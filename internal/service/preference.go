@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"konnect/internal/config"
+	"konnect/internal/database"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUnknownNotificationType = errors.New("unknown notification type")
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+)
+
+// unsubscribePurpose separates unsubscribe signatures from other uses of the jwt secret
+const unsubscribePurpose = "konnect:unsubscribe:"
+
+type NotificationPreferenceService struct {
+	db     *database.DB
+	cfg    *config.Config
+	logger *zap.Logger
+}
+
+func NewNotificationPreferenceService(db *database.DB, cfg *config.Config, logger *logger.Logger) *NotificationPreferenceService {
+	return &NotificationPreferenceService{
+		db:     db,
+		cfg:    cfg,
+		logger: logger.With(zap.String("component", "notification_preference_service")),
+	}
+}
+
+// GetPreferences returns the notification settings of a user, falling back to the defaults. It implements the worker.PreferenceStore interface
+func (s *NotificationPreferenceService) GetPreferences(ctx context.Context, userID uuid.UUID) (*model.NotificationPreference, error) {
+	var prefs model.NotificationPreference
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Take(&prefs).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.DefaultNotificationPreference(userID), nil
+		}
+		s.logError(ctx, err, "failed to get notification preferences", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+	if prefs.Channels == nil {
+		prefs.Channels = model.NotificationChannels{}
+	}
+	return &prefs, nil
+}
+
+// UpdatePreferences replaces the notification settings of a user
+func (s *NotificationPreferenceService) UpdatePreferences(ctx context.Context, userID uuid.UUID, req model.UpdateNotificationPreferencesRequest) (*model.NotificationPreference, error) {
+	for notificationType := range req.Channels {
+		if !notificationType.Configurable() {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNotificationType, notificationType)
+		}
+	}
+
+	prefs := model.DefaultNotificationPreference(userID)
+	if req.Channels != nil {
+		prefs.Channels = req.Channels
+	}
+	prefs.DeliveryMode = req.DeliveryMode
+	if req.DigestFrequency != "" {
+		prefs.DigestFrequency = req.DigestFrequency
+	}
+	prefs.QuietHoursStart = req.QuietHoursStart
+	prefs.QuietHoursEnd = req.QuietHoursEnd
+	if req.Timezone != "" {
+		prefs.Timezone = req.Timezone
+	}
+	prefs.EmailUnsubscribed = req.EmailUnsubscribed
+
+	// upsert preferences by user
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"channels", "delivery_mode", "digest_frequency", "quiet_hours_start", "quiet_hours_end", "timezone", "email_unsubscribed", "updated_at",
+		}),
+	}, clause.Returning{}).Create(prefs).Error; err != nil {
+		s.logError(ctx, err, "failed to update notification preferences", zap.String("user_id", userID.String()))
+		return nil, err
+	}
+
+	return prefs, nil
+}
+
+// Unsubscribe stops all notification emails of the user the token was issued for
+func (s *NotificationPreferenceService) Unsubscribe(ctx context.Context, token string) error {
+	userID, err := s.verifyUnsubscribeToken(token)
+	if err != nil {
+		return err
+	}
+
+	prefs := model.DefaultNotificationPreference(userID)
+	prefs.EmailUnsubscribed = true
+
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email_unsubscribed", "updated_at"}),
+	}).Create(prefs).Error; err != nil {
+		s.logError(ctx, err, "failed to unsubscribe user", zap.String("user_id", userID.String()))
+		return err
+	}
+	return nil
+}
+
+// UnsubscribeURL returns the one-click unsubscribe link for a user's emails. It implements the worker.PreferenceStore interface
+func (s *NotificationPreferenceService) UnsubscribeURL(userID uuid.UUID) string {
+	return fmt.Sprintf("%s/api/notifications/unsubscribe?token=%s", strings.TrimRight(s.cfg.AppBaseURL, "/"), url.QueryEscape(s.signUnsubscribeToken(userID)))
+}
+
+// signUnsubscribeToken creates a token in the format <user id>.<signature>. Tokens do not expire so old emails keep working
+func (s *NotificationPreferenceService) signUnsubscribeToken(userID uuid.UUID) string {
+	return userID.String() + "." + base64.RawURLEncoding.EncodeToString(s.unsubscribeSignature(userID))
+}
+
+func (s *NotificationPreferenceService) verifyUnsubscribeToken(token string) (uuid.UUID, error) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, ErrInvalidUnsubscribeToken
+	}
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, ErrInvalidUnsubscribeToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, s.unsubscribeSignature(userID)) {
+		return uuid.Nil, ErrInvalidUnsubscribeToken
+	}
+	return userID, nil
+}
+
+func (s *NotificationPreferenceService) unsubscribeSignature(userID uuid.UUID) []byte {
+	mac := hmac.New(sha256.New, []byte(s.cfg.JWTSecret))
+	mac.Write([]byte(unsubscribePurpose + userID.String()))
+	return mac.Sum(nil)
+}
+
+func (s *NotificationPreferenceService) logError(ctx context.Context, err error, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Error(msg, append(fields, zap.Error(err))...)
+}
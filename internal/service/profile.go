@@ -1,14 +1,21 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"konnect/internal/cache"
 	"konnect/internal/database"
 	"konnect/internal/logger"
+	"konnect/internal/metrics"
 	"konnect/internal/model"
+	"konnect/internal/worker"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -20,22 +27,35 @@ var (
 	ErrProfileUserNotFound = errors.New("user not found")
 )
 
+// nearest users ranked when sorting nearby profiles by shared interests
+const interestMatchCandidates = 1000
+
 type ProfileService struct {
-	db            *database.DB
-	interestCache *cache.InterestCache
-	logger        *zap.Logger
+	db          *database.DB
+	worker      *asynq.Client
+	syncStats   *cache.SyncStatsCache
+	geoCache    *cache.GeoCache
+	interests   *cache.InterestCache
+	boosts      *BoostService
+	impressions *ImpressionService
+	logger      *zap.Logger
 }
 
-func NewProfileService(db *database.DB, interestCache *cache.InterestCache, logger *logger.Logger) *ProfileService {
+func NewProfileService(db *database.DB, worker *asynq.Client, syncStats *cache.SyncStatsCache, geoCache *cache.GeoCache, interests *cache.InterestCache, boosts *BoostService, impressions *ImpressionService, logger *logger.Logger) *ProfileService {
 	return &ProfileService{
-		db:            db,
-		interestCache: interestCache,
-		logger:        logger.With(zap.String("component", "profile_service")),
+		db:          db,
+		worker:      worker,
+		syncStats:   syncStats,
+		geoCache:    geoCache,
+		interests:   interests,
+		boosts:      boosts,
+		impressions: impressions,
+		logger:      logger.With(zap.String("component", "profile_service")),
 	}
 }
 
 // CreateProfile creates a new profile for a user
-func (s *ProfileService) CreateProfile(profile *model.Profile) error {
+func (s *ProfileService) CreateProfile(ctx context.Context, profile *model.Profile) error {
 	query := `
 		INSERT INTO profiles(user_id, fullname, interests, bio, photo_url, photo_public_id, is_verified, dob, gender, is_gender_public, relationship_intent, latitude, longitude, location)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, ST_Point($14, $15))
@@ -43,7 +63,7 @@ func (s *ProfileService) CreateProfile(profile *model.Profile) error {
 		created_at, updated_at
 	`
 
-	if err := s.db.Raw(query,
+	if err := s.db.WithContext(ctx).Raw(query,
 		profile.UserID,
 		profile.Fullname,
 		profile.Interests,
@@ -60,7 +80,7 @@ func (s *ProfileService) CreateProfile(profile *model.Profile) error {
 		profile.Longitude,
 		profile.Latitude,
 	).Scan(profile).Error; err != nil {
-		s.logError(err, "failed to create profile", zap.String("user_id", profile.UserID.String()))
+		s.logError(ctx, err, "failed to create profile", zap.String("user_id", profile.UserID.String()))
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			return ErrProfileExists
 		}
@@ -71,48 +91,43 @@ func (s *ProfileService) CreateProfile(profile *model.Profile) error {
 		return err
 	}
 
-	// add interests to cache in background
-	go func() {
-		if err := s.interestCache.AddUserInterests(context.Background(), profile.UserID.String(), profile.Interests); err != nil {
-			s.logger.Warn("failed to add user interests to cache on profile creation", zap.Error(err), zap.String("user_id", profile.UserID.String()))
-		}
-	}()
+	// add interests and location to cache in background
+	s.syncInterestCache(ctx, profile.UserID)
 	return nil
 }
 
 // GetProfile retrieves a profile by ID
-func (s *ProfileService) GetProfile(id uuid.UUID) (*model.Profile, error) {
+func (s *ProfileService) GetProfile(ctx context.Context, id uuid.UUID) (*model.Profile, error) {
 	var profile model.Profile
-	if err := s.db.Where("id = ?", id).Take(&profile); err != nil {
+	if err := s.db.WithContext(ctx).Where("id = ?", id).Take(&profile).Error; err != nil {
 		return nil, ErrProfileNotFound
 	}
 	return &profile, nil
 }
 
 // GetProfileByUserID retrieves a profile by user ID
-func (s *ProfileService) GetProfileByUserID(userID uuid.UUID) (*model.Profile, error) {
+func (s *ProfileService) GetProfileByUserID(ctx context.Context, userID uuid.UUID) (*model.Profile, error) {
 	var profile model.Profile
-	if err := s.db.Where("user_id = ?", userID).Take(&profile).Error; err != nil {
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Take(&profile).Error; err != nil {
 		return nil, ErrProfileNotFound
 	}
 	return &profile, nil
 }
 
 // UpdateProfile updates an existing profile
-func (s *ProfileService) UpdateProfileByUserID(userID uuid.UUID, updates *model.Profile) (*model.Profile, error) {
-	// retrieve current profile state
-	currentProfile, err := s.GetProfileByUserID(userID)
-	if err != nil {
+func (s *ProfileService) UpdateProfileByUserID(ctx context.Context, userID uuid.UUID, updates *model.Profile) (*model.Profile, error) {
+	// ensure the profile exists
+	if _, err := s.GetProfileByUserID(ctx, userID); err != nil {
 		return nil, err
 	}
 
 	var profile model.Profile
-	if err := s.db.Model(&profile).
+	if err := s.db.WithContext(ctx).Model(&profile).
 		Where("user_id = ?", userID).
 		Clauses(clause.Returning{}).
 		Updates(updates).
 		Scan(&profile).Error; err != nil {
-		s.logError(err, "failed to update profile", zap.String("user_id", userID.String()))
+		s.logError(ctx, err, "failed to update profile", zap.String("user_id", userID.String()))
 
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			return nil, ErrProfileExists
@@ -123,37 +138,200 @@ func (s *ProfileService) UpdateProfileByUserID(userID uuid.UUID, updates *model.
 		return nil, err
 	}
 
-	// sync with cache if interests were provided
-	if updates.Interests != nil {
-		go func() {
-			err := s.interestCache.UpdateUserInterests(context.Background(), userID.String(), currentProfile.Interests, updates.Interests)
-			if err != nil {
-				s.logger.Warn("failed to update user interests in cache on profile update",
-					zap.Error(err),
-					zap.String("user_id", userID.String()),
-				)
-			}
-		}()
+	// sync with cache if interests or location were provided
+	if updates.Interests != nil || updates.Latitude != 0 || updates.Longitude != 0 {
+		s.syncInterestCache(ctx, userID)
 	}
 
 	return &profile, nil
 }
 
-// GetNearbyProfiles gets profiles within a radius (in meters) of given coordinates
-func (s *ProfileService) GetNearbyProfiles(userID uuid.UUID, lat, lng float64, radiusMeters float64, offset int, limit int) ([]model.Profile, error) {
+// GetNearbyProfiles gets profiles within a radius (in meters) of given coordinates. The redis location index is used when
+// available, postgis otherwise. Boosted users rank as if they were closer, their distance is divided by the boost multiplier.
+// The returned profiles are recorded as seen by the user, excludeSeen leaves out the profiles seen during the last week.
+// Pages returned before are seen, so the offset is ignored when they are left out. Sorting by interests ranks the
+// profiles sharing interests with the user among the nearest ones, it needs the location index and falls back to the
+// distance ranking without it
+func (s *ProfileService) GetNearbyProfiles(ctx context.Context, userID uuid.UUID, lat, lng float64, radiusMeters float64, offset int, limit int, excludeSeen bool, sort model.NearbySort) ([]model.Profile, error) {
+	var seen []string
+	if excludeSeen {
+		offset = 0
+		seenCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+		seen = s.impressions.seenProfiles(seenCtx, userID)
+		cancel()
+	}
+
+	var (
+		ids []string
+		err error
+	)
+	if sort == model.SortByInterests {
+		ids, err = s.searchMatchingInterests(ctx, userID, lat, lng, radiusMeters, offset, limit, seen)
+	} else {
+		ids, err = s.searchLocationIndex(ctx, userID, lat, lng, radiusMeters, offset, limit, seen)
+	}
+	if err == nil {
+		s.trackViews(ctx, userID, ids)
+		return s.getProfilesByUserIDs(ctx, ids)
+	}
+	if !errors.Is(err, cache.ErrGeoIndexMissing) {
+		logger.FromContext(ctx, s.logger).Warn("location index unavailable, falling back to postgis", zap.Error(err))
+	}
+
 	var profiles []model.Profile
 
 	// nearby distance relative to the location. (lon, lat)
-	query := s.db.Where("user_id != ?", userID).
-		Where("ST_DWithin(location, ST_Point(?, ?)::GEOGRAPHY, ?)", lng, lat, radiusMeters)
+	query := s.db.WithContext(ctx).Select("profiles.*").
+		Joins("LEFT JOIN boosts ON boosts.user_id = profiles.user_id AND boosts.starts_at <= NOW() AND boosts.ends_at > NOW() AND boosts.deleted_at IS NULL").
+		Where("profiles.user_id != ?", userID).
+		Where("ST_DWithin(profiles.location, ST_Point(?, ?)::GEOGRAPHY, ?)", lng, lat, radiusMeters)
+	if len(seen) > 0 {
+		query = query.Where("profiles.user_id NOT IN ?", seen)
+	}
+	query = query.Order(clause.Expr{
+		SQL:  "ST_Distance(profiles.location, ST_Point(?, ?)::GEOGRAPHY) / CASE WHEN boosts.id IS NULL THEN 1 ELSE ? END, profiles.user_id",
+		Vars: []any{lng, lat, s.boosts.multiplier()},
+	})
 	if err := query.Limit(limit).Offset(offset).Find(&profiles).Error; err != nil {
-		s.logError(err, "failed to get nearby profiles")
+		s.logError(ctx, err, "failed to get nearby profiles")
 		return nil, err
 	}
 
+	ids = make([]string, len(profiles))
+	for i, profile := range profiles {
+		ids[i] = profile.UserID.String()
+	}
+	s.trackViews(ctx, userID, ids)
 	return profiles, nil
 }
 
-func (s *ProfileService) logError(err error, msg string, fields ...zap.Field) {
-	s.logger.Error(msg, append(fields, zap.Error(err))...)
+// searchLocationIndex returns a page of the user ids within the radius from the redis location index, ranked by their
+// distance. Boosted users are looked up separately since they may rank ahead of nearer users. Excluded users are left out
+func (s *ProfileService) searchLocationIndex(ctx context.Context, userID uuid.UUID, lat, lng float64, radiusMeters float64, offset int, limit int, excluded []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	// extra results in case the user or excluded users are within the radius. Unboosted users on the page are always
+	// among these
+	locations, err := s.geoCache.SearchNearby(ctx, lat, lng, radiusMeters, offset+limit+1+len(excluded))
+	if err != nil {
+		return nil, err
+	}
+
+	rank := make(map[string]float64, len(locations))
+	for _, location := range locations {
+		rank[location.Name] = location.Dist
+	}
+	if boosted := s.boosts.boostedUsers(ctx); len(boosted) > 0 {
+		boostedLocations, err := s.geoCache.GetDistances(ctx, lat, lng, radiusMeters, boosted)
+		if err != nil {
+			return nil, err
+		}
+		for _, location := range boostedLocations {
+			rank[location.Name] = location.Dist / s.boosts.multiplier()
+		}
+	}
+	delete(rank, userID.String())
+	for _, id := range excluded {
+		delete(rank, id)
+	}
+
+	nearby := make([]string, 0, len(rank))
+	for id := range rank {
+		nearby = append(nearby, id)
+	}
+	slices.SortFunc(nearby, func(a, b string) int {
+		if rank[a] != rank[b] {
+			return cmp.Compare(rank[a], rank[b])
+		}
+		return strings.Compare(a, b)
+	})
+
+	if offset >= len(nearby) {
+		return []string{}, nil
+	}
+	return nearby[offset:min(offset+limit, len(nearby))], nil
+}
+
+// searchMatchingInterests returns a page of the user ids sharing interests with the user among the nearest users within
+// the radius, best matches first. Excluded users are left out
+func (s *ProfileService) searchMatchingInterests(ctx context.Context, userID uuid.UUID, lat, lng float64, radiusMeters float64, offset int, limit int, excluded []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	interests, err := s.interests.GetUserInterests(ctx, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	candidatesKey, err := s.geoCache.StoreNearby(ctx, userID.String(), lat, lng, radiusMeters, interestMatchCandidates)
+	if err != nil {
+		return nil, err
+	}
+	// extra results in case excluded users are among the matches
+	matches, err := s.interests.GetUsersWithMatchingInterests(ctx, userID.String(), interests, offset+limit+len(excluded), candidatesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]struct{}, len(excluded))
+	for _, id := range excluded {
+		skip[id] = struct{}{}
+	}
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if _, ok := skip[match.UserID]; !ok {
+			ids = append(ids, match.UserID)
+		}
+	}
+
+	if offset >= len(ids) {
+		return []string{}, nil
+	}
+	return ids[offset:min(offset+limit, len(ids))], nil
+}
+
+// trackViews records the profiles shown to the viewer as impressions and boost views
+func (s *ProfileService) trackViews(ctx context.Context, viewerID uuid.UUID, ids []string) {
+	s.impressions.recordImpressions(ctx, viewerID, ids)
+	s.boosts.recordViews(ctx, ids)
+}
+
+// getProfilesByUserIDs loads the profiles of the users in the order of the given ids
+func (s *ProfileService) getProfilesByUserIDs(ctx context.Context, ids []string) ([]model.Profile, error) {
+	profiles := []model.Profile{}
+	if len(ids) == 0 {
+		return profiles, nil
+	}
+	if err := s.db.WithContext(ctx).Where("user_id IN ?", ids).Find(&profiles).Error; err != nil {
+		s.logError(ctx, err, "failed to get nearby profiles")
+		return nil, err
+	}
+
+	position := make(map[string]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	slices.SortFunc(profiles, func(a, b model.Profile) int {
+		return position[a.UserID.String()] - position[b.UserID.String()]
+	})
+	return profiles, nil
+}
+
+// syncInterestCache enqueues a durable cache sync for the user's interests. Failures are not returned since the profile is
+// already saved, the incremental cache seeding picks up profiles whose sync could not be enqueued
+func (s *ProfileService) syncInterestCache(ctx context.Context, userID uuid.UUID) {
+	err := worker.NewProfileSyncJob(s.worker, model.ProfileSyncPayload{TaskMeta: worker.NewTaskMeta(ctx), UserID: userID, ChangedAt: time.Now()})
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Warn("failed to enqueue interest cache sync", zap.Error(err), zap.String("user_id", userID.String()))
+		metrics.CacheSyncEnqueueFailures.Inc()
+
+		// the request may be over by now
+		statsCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second)
+		defer cancel()
+		s.syncStats.RecordEnqueueFailure(statsCtx)
+	}
+}
+
+func (s *ProfileService) logError(ctx context.Context, err error, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Error(msg, append(fields, zap.Error(err))...)
 }
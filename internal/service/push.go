@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"konnect/internal/config"
+	"konnect/internal/logger"
+	"konnect/internal/worker"
+	"maps"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// NewPushDispatcher returns the push dispatcher selected by the PUSH_PROVIDER config
+func NewPushDispatcher(cfg *config.Config, logger *logger.Logger) (worker.PushDispatcher, error) {
+	switch cfg.PushProvider {
+	case "fcm":
+		return NewFCMService(cfg, logger)
+	case "fake":
+		return NewFakePushService(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown push provider: %s", cfg.PushProvider)
+	}
+}
+
+// FakePush is a push notification recorded by the fake push service
+type FakePush struct {
+	Token string
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// FakePushService is an in-memory push dispatcher for local development and tests. Tokens marked as invalid are rejected like a real provider would
+type FakePushService struct {
+	mu      sync.Mutex
+	sent    []FakePush
+	invalid map[string]struct{}
+	logger  *zap.Logger
+}
+
+func NewFakePushService(logger *logger.Logger) *FakePushService {
+	return &FakePushService{
+		invalid: make(map[string]struct{}),
+		logger:  logger.With(zap.String("component", "fake_push_service")),
+	}
+}
+
+func (s *FakePushService) Send(ctx context.Context, token string, title string, body string, data map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.invalid[token]; ok {
+		return fmt.Errorf("%w: %s", worker.ErrInvalidPushToken, token)
+	}
+
+	s.sent = append(s.sent, FakePush{Token: token, Title: title, Body: body, Data: maps.Clone(data)})
+	logger.FromContext(ctx, s.logger).Info("push notification sent", zap.String("title", title), zap.String("body", body))
+	return nil
+}
+
+// MarkInvalid makes subsequent sends to the given tokens fail with worker.ErrInvalidPushToken
+func (s *FakePushService) MarkInvalid(tokens ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, token := range tokens {
+		s.invalid[token] = struct{}{}
+	}
+}
+
+// Sent returns a copy of all recorded push notifications
+func (s *FakePushService) Sent() []FakePush {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]FakePush(nil), s.sent...)
+}
+
+// Reset clears recorded notifications and invalid tokens
+func (s *FakePushService) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = nil
+	s.invalid = make(map[string]struct{})
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"konnect/internal/config"
+	"konnect/internal/logger"
+	"konnect/internal/worker"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrSMSServer = errors.New("sms server error")
+)
+
+const smsTimeout = 15 * time.Second
+
+// NewSMSDispatcher returns the sms dispatcher selected by the SMS_PROVIDER config
+func NewSMSDispatcher(cfg *config.Config, logger *logger.Logger) (worker.SMSDispatcher, error) {
+	switch cfg.SMSProvider {
+	case "africastalking":
+		if cfg.AfricasTalkingUser == "" || cfg.AfricasTalkingKey == "" {
+			return nil, errors.New("africa's talking credentials not set")
+		}
+		return NewSMSService(cfg, logger), nil
+	case "log":
+		return NewLogSMSService(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown sms provider: %s", cfg.SMSProvider)
+	}
+}
+
+type africasTalkingResponse struct {
+	SMSMessageData struct {
+		Message    string `json:"Message"`
+		Recipients []struct {
+			StatusCode int    `json:"statusCode"`
+			Number     string `json:"number"`
+			Status     string `json:"status"`
+			MessageID  string `json:"messageId"`
+		} `json:"Recipients"`
+	} `json:"SMSMessageData"`
+}
+
+// SMSService sends text messages through the Africa's Talking bulk messaging API
+type SMSService struct {
+	cfg        *config.Config
+	httpClient *resty.Client
+	logger     *zap.Logger
+}
+
+func NewSMSService(cfg *config.Config, logger *logger.Logger) *SMSService {
+	baseURL := "https://api.africastalking.com"
+	if cfg.AfricasTalkingUser == "sandbox" {
+		baseURL = "https://api.sandbox.africastalking.com"
+	}
+
+	// base client with auth header
+	httpClient := resty.New().
+		SetBaseURL(baseURL).
+		SetTimeout(smsTimeout)
+	httpClient.SetHeader("apiKey", cfg.AfricasTalkingKey)
+	httpClient.SetHeader("Accept", "application/json")
+
+	return &SMSService{
+		cfg:        cfg,
+		httpClient: httpClient,
+		logger:     logger.With(zap.String("component", "sms_service")),
+	}
+}
+
+// Send texts the message to the numbers. The request is cancelled with the context of the sms task
+func (s *SMSService) Send(ctx context.Context, phoneNumbers []string, message string) error {
+	form := map[string]string{
+		"username": s.cfg.AfricasTalkingUser,
+		"to":       strings.Join(phoneNumbers, ","),
+		"message":  message,
+	}
+	if s.cfg.SMSSenderID != "" {
+		form["from"] = s.cfg.SMSSenderID
+	}
+
+	var resp africasTalkingResponse
+	res, err := s.httpClient.R().
+		SetContext(ctx).
+		SetFormData(form).
+		SetResult(&resp).
+		Post("/version1/messaging")
+
+	// server error. request possibly hanging
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSMSServer, err)
+	}
+	if res.IsError() {
+		return fmt.Errorf("%w: %s", ErrSMSServer, res.String())
+	}
+
+	// 100, 101 and 102 are the processed, sent and queued statuses
+	sent := 0
+	for _, recipient := range resp.SMSMessageData.Recipients {
+		if recipient.StatusCode >= 100 && recipient.StatusCode <= 102 {
+			sent++
+			continue
+		}
+		logger.FromContext(ctx, s.logger).Warn("sms delivery rejected", zap.String("number", recipient.Number), zap.String("status", recipient.Status))
+	}
+
+	// a retry would resend to numbers that already received the message
+	if sent == 0 {
+		return fmt.Errorf("%w: %s", ErrSMSServer, resp.SMSMessageData.Message)
+	}
+	return nil
+}
+
+// LogSMSService writes text messages to the logs instead of sending them. It is meant for local development
+type LogSMSService struct {
+	logger *zap.Logger
+}
+
+func NewLogSMSService(logger *logger.Logger) *LogSMSService {
+	return &LogSMSService{
+		logger: logger.With(zap.String("component", "log_sms_service")),
+	}
+}
+
+func (s *LogSMSService) Send(ctx context.Context, phoneNumbers []string, message string) error {
+	logger.FromContext(ctx, s.logger).Info("sms sent", zap.Strings("phone_numbers", phoneNumbers), zap.String("message", message))
+	return nil
+}
@@ -1,12 +1,16 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"konnect/internal/database"
 	"konnect/internal/logger"
+	"konnect/internal/metrics"
 	"konnect/internal/model"
+	"konnect/internal/tracing"
 	"konnect/internal/worker"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
@@ -15,42 +19,60 @@ import (
 )
 
 var (
-	ErrAlreadySwiped = errors.New("user has already swiped on this profile")
-	ErrSwipeNotFound = errors.New("swipe not found")
-	ErrSelfSwipe     = errors.New("user cannot swipe on their own profile")
+	ErrAlreadySwiped    = errors.New("user has already swiped on this profile")
+	ErrSwipeNotFound    = errors.New("swipe not found")
+	ErrSelfSwipe        = errors.New("user cannot swipe on their own profile")
+	ErrLikeLimitReached = errors.New("daily like limit reached")
+	ErrRewindMatched    = errors.New("swipes that created a match cannot be rewound")
 )
 
 type SwipeService struct {
-	db     *database.DB
-	worker *asynq.Client
-	logger *zap.Logger
+	db      *database.DB
+	worker  *asynq.Client
+	billing *BillingService
+	logger  *zap.Logger
 }
 
-func NewSwipeService(db *database.DB, worker *asynq.Client, logger *logger.Logger) *SwipeService {
+func NewSwipeService(db *database.DB, worker *asynq.Client, billing *BillingService, logger *logger.Logger) *SwipeService {
 	return &SwipeService{
-		db:     db,
-		worker: worker,
-		logger: logger.With(zap.String("component", "swipe_service")),
+		db:      db,
+		worker:  worker,
+		billing: billing,
+		logger:  logger.With(zap.String("component", "swipe_service")),
 	}
 }
 
 // CreateSwipe creates a new swipe and checks for a match if the swipe is a 'like'. A match is returned if any
-func (s *SwipeService) CreateSwipe(swipe *model.Swipe) (*model.Swipe, *model.Match, error) {
+func (s *SwipeService) CreateSwipe(ctx context.Context, swipe *model.Swipe) (_ *model.Swipe, match *model.Match, err error) {
+	ctx, span := tracing.Start(ctx, "SwipeService.CreateSwipe")
+	defer func() { tracing.End(span, err) }()
+
 	if swipe.SwiperID == swipe.SwipeeID {
 		return nil, nil, ErrSelfSwipe
 	}
-
-	var match *model.Match
+	var likeLimit int
+	if swipe.SwipeType == model.Like {
+		entitlements, err := s.billing.GetEntitlements(ctx, swipe.SwiperID)
+		if err != nil {
+			return nil, nil, err
+		}
+		likeLimit = entitlements.DailyLikeLimit
+	}
 
 	// check mutual swipe and create a match
-	err := s.db.Transaction(func(tx *gorm.DB) error {
-		// 1. create swipe
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. create swipe, within the like allowance of the swiper
+		if likeLimit > 0 {
+			if err := s.checkLikeLimit(ctx, tx, swipe.SwiperID, likeLimit); err != nil {
+				return err
+			}
+		}
 		if err := tx.Create(swipe).Error; err != nil {
 			if errors.Is(err, gorm.ErrDuplicatedKey) {
 				return ErrAlreadySwiped
 			}
 
-			s.logError(err, "failed to create swipe",
+			s.logError(ctx, err, "failed to create swipe",
 				zap.String("swiperId", swipe.SwiperID.String()),
 				zap.String("swipeeId", swipe.SwipeeID.String()),
 			)
@@ -66,7 +88,7 @@ func (s *SwipeService) CreateSwipe(swipe *model.Swipe) (*model.Swipe, *model.Mat
 		if err != nil {
 			// any error aside record not found is a fatal exception
 			if !errors.Is(err, gorm.ErrRecordNotFound) {
-				s.logError(err, "failed to check for reverse swipe",
+				s.logError(ctx, err, "failed to check for reverse swipe",
 					zap.String("swiperId", swipe.SwipeeID.String()),
 					zap.String("swipeeId", swipe.SwiperID.String()),
 				)
@@ -83,13 +105,13 @@ func (s *SwipeService) CreateSwipe(swipe *model.Swipe) (*model.Swipe, *model.Mat
 		if err := tx.Create(newMatch).Error; err != nil {
 			// match exist
 			if errors.Is(err, gorm.ErrDuplicatedKey) {
-				s.logger.Warn("match already exists",
+				logger.FromContext(ctx, s.logger).Warn("match already exists",
 					zap.String("user1Id", newMatch.User1ID.String()),
 					zap.String("user2Id", newMatch.User2ID.String()),
 				)
 				return nil
 			}
-			s.logError(err, "failed to create match",
+			s.logError(ctx, err, "failed to create match",
 				zap.String("user1Id", newMatch.User1ID.String()),
 				zap.String("user2Id", newMatch.User2ID.String()),
 			)
@@ -104,26 +126,113 @@ func (s *SwipeService) CreateSwipe(swipe *model.Swipe) (*model.Swipe, *model.Mat
 		return nil, nil, err
 	}
 
+	metrics.SwipesTotal.WithLabelValues(string(swipe.SwipeType)).Inc()
+	if match != nil {
+		metrics.MatchesTotal.Inc()
+	}
 	return swipe, match, nil
 }
 
 // GetSwipeHistory retrieves a history of swipes made by a given user
-func (s *SwipeService) GetSwipeHistory(userID uuid.UUID, limit, offset int) ([]model.Swipe, error) {
+func (s *SwipeService) GetSwipeHistory(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Swipe, error) {
 	var swipes []model.Swipe
 
-	query := s.db.Where("swiper_id = ?", userID).Joins("Swipee")
+	query := s.db.WithContext(ctx).Where("swiper_id = ?", userID).Joins("Swipee")
+	if err := query.Limit(limit).Offset(offset).Find(&swipes).Error; err != nil {
+		s.logError(ctx, err, "failed to get swipe history")
+		return nil, err
+	}
+
+	return swipes, nil
+}
+
+// GetLikesReceived retrieves the likes of users the given user has not swiped on yet, most recent first. Premium only
+func (s *SwipeService) GetLikesReceived(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Swipe, error) {
+	if err := s.billing.RequireFeature(ctx, userID, model.FeatureSeeLikes); err != nil {
+		return nil, err
+	}
+
+	var swipes []model.Swipe
+	query := s.db.WithContext(ctx).Where("swipee_id = ? AND swipe_type = ?", userID, model.Like).
+		Where("NOT EXISTS (SELECT 1 FROM swipes own WHERE own.swiper_id = ? AND own.swipee_id = swipes.swiper_id AND own.deleted_at IS NULL)", userID).
+		Joins("Swiper").
+		Order("swipes.created_at DESC")
 	if err := query.Limit(limit).Offset(offset).Find(&swipes).Error; err != nil {
-		s.logError(err, "failed to get swipe history")
+		s.logError(ctx, err, "failed to get likes received", zap.String("user_id", userID.String()))
 		return nil, err
 	}
 
 	return swipes, nil
 }
 
+// RewindLastSwipe undoes the most recent swipe of a user so the profile can be swiped on again. Premium only
+func (s *SwipeService) RewindLastSwipe(ctx context.Context, userID uuid.UUID) (*model.Swipe, error) {
+	if err := s.billing.RequireFeature(ctx, userID, model.FeatureRewind); err != nil {
+		return nil, err
+	}
+
+	var swipe model.Swipe
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("swiper_id = ?", userID).Order("created_at DESC").Take(&swipe).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSwipeNotFound
+			}
+			return err
+		}
+
+		// a match cannot be taken back by one side
+		if swipe.SwipeType == model.Like {
+			var matches int64
+			if err := tx.Model(&model.Match{}).
+				Where("(user1_id = ? AND user2_id = ?) OR (user1_id = ? AND user2_id = ?)", swipe.SwiperID, swipe.SwipeeID, swipe.SwipeeID, swipe.SwiperID).
+				Count(&matches).Error; err != nil {
+				return err
+			}
+			if matches > 0 {
+				return ErrRewindMatched
+			}
+		}
+
+		// hard delete so the unique swiper and swipee pair is free again
+		return tx.Unscoped().Delete(&swipe).Error
+	})
+	if err != nil {
+		if !errors.Is(err, ErrSwipeNotFound) && !errors.Is(err, ErrRewindMatched) {
+			s.logError(ctx, err, "failed to rewind swipe", zap.String("user_id", userID.String()))
+		}
+		return nil, err
+	}
+
+	return &swipe, nil
+}
+
+// checkLikeLimit returns ErrLikeLimitReached when the user used their allowance of likes over the last 24 hours. It runs
+// in the transaction creating the like, which holds a lock on the likes of the user so concurrent likes are counted
+// one after the other
+func (s *SwipeService) checkLikeLimit(ctx context.Context, tx *gorm.DB, userID uuid.UUID, limit int) error {
+	// released when the transaction ends
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", "likes:"+userID.String()).Error; err != nil {
+		s.logError(ctx, err, "failed to lock likes", zap.String("user_id", userID.String()))
+		return err
+	}
+
+	var likes int64
+	if err := tx.Model(&model.Swipe{}).
+		Where("swiper_id = ? AND swipe_type = ? AND created_at > ?", userID, model.Like, time.Now().Add(-24*time.Hour)).
+		Count(&likes).Error; err != nil {
+		s.logError(ctx, err, "failed to count likes", zap.String("user_id", userID.String()))
+		return err
+	}
+	if likes >= int64(limit) {
+		return ErrLikeLimitReached
+	}
+	return nil
+}
+
 // GetSwipeByID retrieves the swipe details and associated swiper and swipee
-func (s *SwipeService) GetSwipeByID(id uuid.UUID) (*model.Swipe, error) {
+func (s *SwipeService) GetSwipeByID(ctx context.Context, id uuid.UUID) (*model.Swipe, error) {
 	var swipe model.Swipe
-	if err := s.db.
+	if err := s.db.WithContext(ctx).
 		Joins("Swiper").
 		Joins("Swipee").
 		Take(&swipe, id).Error; err != nil {
@@ -135,20 +244,80 @@ func (s *SwipeService) GetSwipeByID(id uuid.UUID) (*model.Swipe, error) {
 	return &swipe, nil
 }
 
-func (s *SwipeService) SendMatchNotification(swipe *model.Swipe) error {
+// SendMatchNotification emails the user whose profile was swiped on and notifies both users in-app and by push. A
+// failure to enqueue one notification does not hold back the others, the failures are returned together
+func (s *SwipeService) SendMatchNotification(ctx context.Context, swipe *model.Swipe) error {
+	var errs []error
+
 	// send message to only the user whose profile was swiped on
-	message := fmt.Sprintf("It's a match! You and @%s both liked each other. Start chatting now!", swipe.Swiper.Username)
 	err := worker.NewEmailDeliveryJob(s.worker, model.EmailPayload{
-		Email:   swipe.Swipee.Email,
-		Subject: "New Konnect Match!",
-		Message: message,
+		TaskMeta:   worker.NewTaskMeta(ctx),
+		Email:      swipe.Swipee.Email,
+		UserID:     swipe.SwipeeID,
+		Type:       model.MatchNotification,
+		TemplateID: string(model.MatchNotification),
+		Locale:     swipe.Swipee.Locale,
+		Data:       map[string]any{"username": swipe.Swiper.Username},
 	})
 	if err != nil {
-		s.logger.Error("Failed to send notification to swiper", zap.Error(err))
+		logger.FromContext(ctx, s.logger).Error("Failed to send match email", zap.String("user_id", swipe.SwipeeID.String()), zap.Error(err))
+		errs = append(errs, err)
+	}
+
+	// both parties get the match in their notification center
+	matches := []struct {
+		recipient uuid.UUID
+		other     *model.User
+	}{
+		{recipient: swipe.SwipeeID, other: swipe.Swiper},
+		{recipient: swipe.SwiperID, other: swipe.Swipee},
+	}
+	for _, m := range matches {
+		err := s.sendUserNotification(ctx, model.InAppPayload{
+			UserID: m.recipient,
+			Type:   model.MatchNotification,
+			Title:  "New Konnect Match!",
+			Body:   fmt.Sprintf("You and @%s both liked each other. Start chatting now!", m.other.Username),
+			Data:   map[string]string{"userId": m.other.ID.String()},
+		})
+		if err != nil {
+			logger.FromContext(ctx, s.logger).Error("Failed to send match notification", zap.String("user_id", m.recipient.String()), zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SendLikeNotification notifies the user whose profile was liked without revealing the swiper
+func (s *SwipeService) SendLikeNotification(ctx context.Context, swipe *model.Swipe) error {
+	err := s.sendUserNotification(ctx, model.InAppPayload{
+		UserID: swipe.SwipeeID,
+		Type:   model.LikeNotification,
+		Title:  "Someone likes you!",
+		Body:   "Someone liked your profile. Keep swiping to find out who.",
+	})
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("Failed to send like notification", zap.String("user_id", swipe.SwipeeID.String()), zap.Error(err))
 	}
 	return err
 }
 
-func (s *SwipeService) logError(err error, msg string, fields ...zap.Field) {
-	s.logger.Error(msg, append(fields, zap.Error(err))...)
+// sendUserNotification fans a notification out to the user's notification center and devices
+func (s *SwipeService) sendUserNotification(ctx context.Context, notification model.InAppPayload) error {
+	notification.TaskMeta = worker.NewTaskMeta(ctx)
+	// the push is sent even when the in-app notification could not be
+	inAppErr := worker.NewInAppDeliveryJob(s.worker, notification)
+	pushErr := worker.NewPushDeliveryJob(s.worker, model.PushPayload{
+		TaskMeta: notification.TaskMeta,
+		UserID:   notification.UserID,
+		Type:     notification.Type,
+		Title:    notification.Title,
+		Body:     notification.Body,
+		Data:     notification.Data,
+	})
+	return errors.Join(inAppErr, pushErr)
+}
+
+func (s *SwipeService) logError(ctx context.Context, err error, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Error(msg, append(fields, zap.Error(err))...)
 }
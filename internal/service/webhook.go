@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"konnect/internal/database"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/worker"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrInvalidWebhookOrigin    = errors.New("webhook sent from an unknown origin")
+	ErrInvalidWebhookPayload   = errors.New("invalid webhook payload")
+)
+
+const paystackProvider = "paystack"
+
+type WebhookService struct {
+	db       *database.DB
+	worker   *asynq.Client
+	paystack *PaystackService
+	billing  *BillingService
+	logger   *zap.Logger
+}
+
+func NewWebhookService(db *database.DB, worker *asynq.Client, paystack *PaystackService, billing *BillingService, logger *logger.Logger) *WebhookService {
+	return &WebhookService{
+		db:       db,
+		worker:   worker,
+		paystack: paystack,
+		billing:  billing,
+		logger:   logger.With(zap.String("component", "webhook_service")),
+	}
+}
+
+// ReceivePaystackEvent verifies a paystack webhook, stores it and enqueues its processing. Redeliveries of a stored
+// event are only enqueued again while the event is not processed
+func (s *WebhookService) ReceivePaystackEvent(ctx context.Context, payload []byte, signature, ip string) error {
+	if !s.paystack.IsValidOrigin(ip) {
+		return ErrInvalidWebhookOrigin
+	}
+	if !s.paystack.VerifyWebhookSignature(payload, signature) {
+		return ErrInvalidWebhookSignature
+	}
+
+	var body model.PaystackEvent
+	if err := json.Unmarshal(payload, &body); err != nil || body.Event == "" {
+		return ErrInvalidWebhookPayload
+	}
+
+	event := &model.WebhookEvent{
+		Provider: paystackProvider,
+		EventID:  paystackEventID(body, payload),
+		Event:    body.Event,
+		Payload:  string(payload),
+		Status:   model.WebhookReceived,
+	}
+	// keep the first delivery of an event
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "provider"}, {Name: "event_id"}},
+		DoNothing: true,
+	}).Create(event).Error; err != nil {
+		s.logError(ctx, err, "failed to store webhook event", zap.String("event_id", event.EventID))
+		return err
+	}
+	if err := s.db.WithContext(ctx).Where("provider = ? AND event_id = ?", paystackProvider, event.EventID).Take(event).Error; err != nil {
+		s.logError(ctx, err, "failed to get webhook event", zap.String("event_id", event.EventID))
+		return err
+	}
+
+	if event.Status == model.WebhookProcessed || event.Status == model.WebhookIgnored {
+		return nil
+	}
+	if err := worker.NewWebhookEventJob(s.worker, model.WebhookEventPayload{TaskMeta: worker.NewTaskMeta(ctx), EventID: event.ID}); err != nil {
+		s.logError(ctx, err, "failed to enqueue webhook event", zap.String("event_id", event.EventID))
+		return err
+	}
+	return nil
+}
+
+// ProcessWebhookEvent applies a stored webhook event. Redeliveries may process an event in several jobs, applying it
+// again has no effect. It implements the worker.WebhookEventHandler interface
+func (s *WebhookService) ProcessWebhookEvent(ctx context.Context, id uuid.UUID) error {
+	var event model.WebhookEvent
+	if err := s.db.WithContext(ctx).Where("id = ?", id).Take(&event).Error; err != nil {
+		return err
+	}
+	if event.Status == model.WebhookProcessed || event.Status == model.WebhookIgnored {
+		return nil
+	}
+
+	status, err := s.processPaystackEvent(ctx, &event)
+	if err != nil {
+		s.logError(ctx, err, "failed to process webhook event", zap.String("event_id", event.EventID), zap.String("event", event.Event))
+		// another job may have processed the event meanwhile
+		updateErr := s.db.WithContext(ctx).Model(&event).Where("status NOT IN ?", []model.WebhookEventStatus{model.WebhookProcessed, model.WebhookIgnored}).
+			Updates(map[string]any{"status": model.WebhookFailed, "error": err.Error()}).Error
+		if updateErr != nil {
+			s.logError(ctx, updateErr, "failed to mark webhook event as failed", zap.String("event_id", event.EventID))
+		}
+		return err
+	}
+
+	return s.db.WithContext(ctx).Model(&event).Updates(map[string]any{"status": status, "error": "", "processed_at": time.Now()}).Error
+}
+
+// processPaystackEvent acts on the events the app cares about. Other events are ignored
+func (s *WebhookService) processPaystackEvent(ctx context.Context, event *model.WebhookEvent) (model.WebhookEventStatus, error) {
+	var body model.PaystackEvent
+	if err := json.Unmarshal([]byte(event.Payload), &body); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+
+	switch body.Event {
+	case model.PaystackChargeSuccess:
+		var transaction model.PaystackTransaction
+		if err := json.Unmarshal(body.Data, &transaction); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+		}
+		if _, err := s.billing.ApplyTransaction(ctx, &transaction); err != nil {
+			// charges made outside the app, e.g. from the paystack dashboard
+			if errors.Is(err, ErrPaymentNotFound) {
+				return model.WebhookIgnored, nil
+			}
+			return "", err
+		}
+		return model.WebhookProcessed, nil
+
+	case model.PaystackSubscriptionCreate, model.PaystackSubscriptionNotRenew, model.PaystackSubscriptionDisable:
+		var subscription model.PaystackSubscription
+		if err := json.Unmarshal(body.Data, &subscription); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+		}
+		// access lasts until the end of the paid period, only the renewal stops
+		autoRenew := body.Event == model.PaystackSubscriptionCreate
+		if err := s.billing.SetAutoRenew(ctx, &subscription, autoRenew); err != nil {
+			return "", err
+		}
+		return model.WebhookProcessed, nil
+
+	default:
+		return model.WebhookIgnored, nil
+	}
+}
+
+// paystackEventID identifies an event across redeliveries. Paystack has no event ids, the event name and the id of
+// its data are used, falling back to a hash of the body
+func paystackEventID(body model.PaystackEvent, payload []byte) string {
+	var data struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(body.Data, &data); err == nil && data.ID != 0 {
+		return fmt.Sprintf("%s:%d", body.Event, data.ID)
+	}
+	sum := sha256.Sum256(payload)
+	return body.Event + ":" + hex.EncodeToString(sum[:])
+}
+
+func (s *WebhookService) logError(ctx context.Context, err error, msg string, fields ...zap.Field) {
+	logger.FromContext(ctx, s.logger).Error(msg, append(fields, zap.Error(err))...)
+}
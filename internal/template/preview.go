@@ -0,0 +1,42 @@
+package template
+
+import "maps"
+
+// previewUnsubscribeURL is a placeholder link for templates with an unsubscribe footer
+const previewUnsubscribeURL = "https://konnect.app/api/notifications/unsubscribe?token=preview"
+
+// previewData holds sample data for rendering template previews
+var previewData = map[string]map[string]any{
+	"match": {
+		"username":       "CharmingKente42",
+		"unsubscribeUrl": previewUnsubscribeURL,
+	},
+	"like": {
+		"unsubscribeUrl": previewUnsubscribeURL,
+	},
+	"message": {
+		"username":       "CharmingKente42",
+		"preview":        "Hey! Are you going to Tidal Rave this year?",
+		"unsubscribeUrl": previewUnsubscribeURL,
+	},
+	"digest": {
+		"frequency":      "daily",
+		"likes":          3,
+		"matches":        1,
+		"messages":       2,
+		"unsubscribeUrl": previewUnsubscribeURL,
+	},
+	"verification": {
+		"username": "CharmingKente42",
+	},
+}
+
+// PreviewData returns the sample data of a template overridden by the provided values
+func PreviewData(id string, overrides map[string]any) map[string]any {
+	data := maps.Clone(previewData[id])
+	if data == nil {
+		data = make(map[string]any)
+	}
+	maps.Copy(data, overrides)
+	return data
+}
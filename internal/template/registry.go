@@ -0,0 +1,172 @@
+package template
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+const (
+	DefaultLocale = "en"
+
+	emailTemplatesDir = "templates/email"
+	emailLayoutFile   = "layout.html"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("email template not found")
+)
+
+// Email is a rendered email ready to be dispatched. HTML is empty for templates without an html variant
+type Email struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// TemplateInfo describes a registered email template and the locales it is available in
+type TemplateInfo struct {
+	ID      string   `json:"id"`
+	Locales []string `json:"locales"`
+}
+
+type emailTemplate struct {
+	// text defines the subject block and the plain text body
+	text *texttemplate.Template
+	// html is optional and rendered inside the shared layout
+	html *htmltemplate.Template
+}
+
+// Registry holds the email templates keyed by template id and locale. Template ids match the notification types
+type Registry struct {
+	templates map[string]map[string]*emailTemplate
+}
+
+// NewRegistry parses all embedded email templates. Every template needs a .txt file with a "subject" block while the .html file is optional
+func NewRegistry() (*Registry, error) {
+	layout, err := htmltemplate.ParseFS(templatesFS, path.Join(emailTemplatesDir, emailLayoutFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email layout: %w", err)
+	}
+
+	registry := &Registry{templates: make(map[string]map[string]*emailTemplate)}
+
+	locales, err := fs.ReadDir(templatesFS, emailTemplatesDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, locale := range locales {
+		if !locale.IsDir() {
+			continue
+		}
+
+		dir := path.Join(emailTemplatesDir, locale.Name())
+		files, err := fs.Glob(templatesFS, path.Join(dir, "*.txt"))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			id := strings.TrimSuffix(path.Base(file), ".txt")
+
+			text, err := texttemplate.New(path.Base(file)).Option("missingkey=error").ParseFS(templatesFS, file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse email template %s: %w", file, err)
+			}
+			if text.Lookup("subject") == nil {
+				return nil, fmt.Errorf("email template %s has no subject block", file)
+			}
+			tmpl := &emailTemplate{text: text}
+
+			// html variants are optional, the text body is used as fallback
+			htmlFile := path.Join(dir, id+".html")
+			if _, err := fs.Stat(templatesFS, htmlFile); err == nil {
+				html, err := htmltemplate.Must(layout.Clone()).Option("missingkey=error").ParseFS(templatesFS, htmlFile)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse email template %s: %w", htmlFile, err)
+				}
+				tmpl.html = html
+			}
+
+			if registry.templates[id] == nil {
+				registry.templates[id] = make(map[string]*emailTemplate)
+			}
+			registry.templates[id][locale.Name()] = tmpl
+		}
+	}
+
+	return registry, nil
+}
+
+// Render renders a template in the requested locale. Regional locales fall back to their base language and then the default locale
+func (r *Registry) Render(id string, locale string, data map[string]any) (*Email, error) {
+	tmpl, err := r.lookup(id, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	var subject, text bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("failed to render subject of %s: %w", id, err)
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render text body of %s: %w", id, err)
+	}
+
+	email := &Email{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()),
+	}
+
+	if tmpl.html != nil {
+		var html bytes.Buffer
+		if err := tmpl.html.ExecuteTemplate(&html, "layout", data); err != nil {
+			return nil, fmt.Errorf("failed to render html body of %s: %w", id, err)
+		}
+		email.HTML = html.String()
+	}
+
+	return email, nil
+}
+
+// Templates lists the registered templates sorted by id
+func (r *Registry) Templates() []TemplateInfo {
+	infos := make([]TemplateInfo, 0, len(r.templates))
+	for id, locales := range r.templates {
+		info := TemplateInfo{ID: id, Locales: make([]string, 0, len(locales))}
+		for locale := range locales {
+			info.Locales = append(info.Locales, locale)
+		}
+		sort.Strings(info.Locales)
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+func (r *Registry) lookup(id string, locale string) (*emailTemplate, error) {
+	locales, ok := r.templates[id]
+	if !ok {
+		return nil, ErrTemplateNotFound
+	}
+
+	// e.g. fr-CA -> fr -> en
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	base, _, _ := strings.Cut(locale, "-")
+	for _, candidate := range []string{locale, base, DefaultLocale} {
+		if tmpl, ok := locales[candidate]; ok {
+			return tmpl, nil
+		}
+	}
+	return nil, ErrTemplateNotFound
+}
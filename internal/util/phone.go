@@ -0,0 +1,45 @@
+package util
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+// NormalizePhoneNumber converts a local or international phone number into the E.164 format (+233241234567).
+// Numbers without an international prefix are assumed to belong to the default country code
+func NormalizePhoneNumber(phone string, defaultCountryCode string) (string, error) {
+	// drop common formatting characters
+	phone = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '(', ')', '.':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(phone))
+
+	switch {
+	case strings.HasPrefix(phone, "+"):
+		phone = strings.TrimPrefix(phone, "+")
+	case strings.HasPrefix(phone, "00"):
+		phone = strings.TrimPrefix(phone, "00")
+	case strings.HasPrefix(phone, "0"):
+		phone = defaultCountryCode + strings.TrimPrefix(phone, "0")
+	case !strings.HasPrefix(phone, defaultCountryCode) || len(phone) <= 10:
+		// local number without the trunk prefix
+		phone = defaultCountryCode + phone
+	}
+
+	// e.164 numbers have at most 15 digits
+	if len(phone) < 8 || len(phone) > 15 || phone[0] == '0' {
+		return "", ErrInvalidPhoneNumber
+	}
+	for _, r := range phone {
+		if r < '0' || r > '9' {
+			return "", ErrInvalidPhoneNumber
+		}
+	}
+
+	return "+" + phone, nil
+}
@@ -4,6 +4,8 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"strings"
+	"unicode"
 )
 
 var (
@@ -41,6 +43,34 @@ func GenerateRandomUsername() string {
 	return fmt.Sprintf("%s%s%d", adjective, noun, num)
 }
 
+// GenerateNumericCode creates a random numeric code with the given number of digits, e.g. for one-time passwords
+func GenerateNumericCode(digits int) string {
+	code := make([]byte, digits)
+	for i := range code {
+		code[i] = byte('0' + randomNumber(0, 9))
+	}
+	return string(code)
+}
+
+// Slugify turns a display name into a lowercase url-safe identifier, e.g. "Sip & Paint" -> "sip-paint"
+func Slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		// collapse separators into a single dash
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
 // randomElement returns a random element from a slice
 func randomElement(slice []string) string {
 	n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(slice))))
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/hibiken/asynq"
+)
+
+// unique task type for the boost expiry job
+const (
+	TypeExpireBoosts = "boost:expire"
+)
+
+// the service that closes ended boosts
+type BoostExpirer interface {
+	ExpireBoosts(ctx context.Context) error
+}
+
+// NewExpireBoostsTask creates the periodic task that records the performance of ended boosts
+func NewExpireBoostsTask() *asynq.Task {
+	return asynq.NewTask(TypeExpireBoosts, nil)
+}
+
+// ExpireBoostsProcessor implements asynq.Handler interface
+type ExpireBoostsProcessor struct {
+	Boosts BoostExpirer
+}
+
+func (p *ExpireBoostsProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	return p.Boosts.ExpireBoosts(ctx)
+}
+
+func NewExpireBoostsProcessor(boosts BoostExpirer) *ExpireBoostsProcessor {
+	return &ExpireBoostsProcessor{
+		Boosts: boosts,
+	}
+}
@@ -2,23 +2,46 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"konnect/internal/cache"
 	"konnect/internal/database"
 	"konnect/internal/logger"
+	"konnect/internal/model"
 	"konnect/internal/script"
 	"log"
+	"time"
 
 	"github.com/hibiken/asynq"
 )
 
-// unique job type for the cache seeding
+// unique job types for the cache seeding
 const (
 	TypeSeedCache = "seed:cache"
+	TypeSeedGeo   = "seed:geo"
 )
 
+// NewCacheSeedingTask creates the task that reseeds the interest cache, incrementally or as a full reconciliation
+func NewCacheSeedingTask(reconcile bool) (*asynq.Task, error) {
+	payload, err := json.Marshal(model.SeedCachePayload{Reconcile: reconcile})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeSeedCache, payload), nil
+}
+
+// NewCacheSeedingJob enqueues a reseed right away, e.g. when a worker boots with an empty cache.
+// Replicas booting together share a single job
 func NewCacheSeedingJob(client *asynq.Client) error {
-	task := asynq.NewTask(TypeSeedCache, nil)
-	info, err := client.Enqueue(task, asynq.Queue("critical"), asynq.MaxRetry(5))
+	task, err := NewCacheSeedingTask(false)
+	if err != nil {
+		return err
+	}
+	info, err := client.Enqueue(task, asynq.Queue(CriticalQueue), asynq.MaxRetry(5), asynq.Unique(10*time.Minute))
+	if errors.Is(err, asynq.ErrDuplicateTask) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
@@ -34,8 +57,16 @@ type CacheSeederProcessor struct {
 }
 
 func (p *CacheSeederProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	// tasks enqueued before payloads existed seed incrementally
+	var payload model.SeedCachePayload
+	if len(t.Payload()) > 0 {
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal seed cache payload: %v: %w", err, asynq.SkipRetry)
+		}
+	}
+
 	// proceed with seeding
-	return script.SeedInterestCache(p.db, p.interestsCache, p.logger)
+	return script.SeedInterestCache(p.db, p.interestsCache, payload.Reconcile, p.logger)
 }
 
 func NewCacheSeederProcessor(db *database.DB, interestsCache *cache.InterestCache, logger *logger.Logger) *CacheSeederProcessor {
@@ -45,3 +76,41 @@ func NewCacheSeederProcessor(db *database.DB, interestsCache *cache.InterestCach
 		logger:         logger,
 	}
 }
+
+// NewGeoSeedingTask creates the task that rebuilds the profile location index from postgres
+func NewGeoSeedingTask() *asynq.Task {
+	return asynq.NewTask(TypeSeedGeo, nil)
+}
+
+// NewGeoSeedingJob enqueues a location index rebuild right away so nearby searches stop falling back to postgis.
+// Replicas booting together share a single job
+func NewGeoSeedingJob(client *asynq.Client) error {
+	info, err := client.Enqueue(NewGeoSeedingTask(), asynq.Queue(CriticalQueue), asynq.MaxRetry(5), asynq.Unique(10*time.Minute))
+	if errors.Is(err, asynq.ErrDuplicateTask) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("enqueued geo seeding job: id=%s queue=%s\n", info.ID, info.Queue)
+	return nil
+}
+
+// GeoSeederProcessor implements asynq.Handler interface
+type GeoSeederProcessor struct {
+	db       *database.DB
+	geoCache *cache.GeoCache
+	logger   *logger.Logger
+}
+
+func (p *GeoSeederProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	return script.SeedGeoIndex(p.db, p.geoCache, p.logger)
+}
+
+func NewGeoSeederProcessor(db *database.DB, geoCache *cache.GeoCache, logger *logger.Logger) *GeoSeederProcessor {
+	return &GeoSeederProcessor{
+		db:       db,
+		geoCache: geoCache,
+		logger:   logger,
+	}
+}
@@ -6,6 +6,7 @@ const (
 	EmailQueue    = "email"
 	SMSQueue      = "sms"
 	InAppQueue    = "inapp"
+	PushQueue     = "push"
 	DefaultQueue  = "default"
 	LowQueue      = "low"
 )
@@ -16,6 +17,7 @@ var Queues = map[string]int{
 	EmailQueue:    3,
 	SMSQueue:      3,
 	InAppQueue:    5,
+	PushQueue:     4,
 	DefaultQueue:  3,
 	LowQueue:      1,
 }
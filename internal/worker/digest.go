@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"konnect/internal/model"
+
+	"github.com/hibiken/asynq"
+)
+
+// unique task type for the digest email job
+const (
+	TypeDigestEmail = "digest:email"
+)
+
+// the digest builder that summarizes activity and enqueues the digest emails
+type DigestSender interface {
+	SendDigests(ctx context.Context, frequency model.DigestFrequency) error
+}
+
+// NewDigestTask creates the periodic task that sends digests of a frequency
+func NewDigestTask(frequency model.DigestFrequency) (*asynq.Task, error) {
+	payload, err := json.Marshal(model.DigestPayload{Frequency: frequency})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeDigestEmail, payload), nil
+}
+
+// DigestProcessor implements asynq.Handler interface
+type DigestProcessor struct {
+	Digests DigestSender
+}
+
+func (p *DigestProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.DigestPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal digest payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	return p.Digests.SendDigests(ctx, payload.Frequency)
+}
+
+func NewDigestProcessor(digests DigestSender) *DigestProcessor {
+	return &DigestProcessor{
+		Digests: digests,
+	}
+}
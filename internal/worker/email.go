@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"konnect/internal/model"
+	"konnect/internal/template"
+	"konnect/internal/tracing"
 	"log"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 )
 
@@ -15,19 +19,23 @@ const (
 	TypeEmailDelivery = "email:delivery"
 )
 
-// the email sender
+// the email sender. html is empty for templates without an html variant. unsubscribeURL is empty for transactional
+// emails, others send it in the List-Unsubscribe headers so mail clients can offer one-click unsubscribe
 type EmailDispatcher interface {
-	Send(email string, message string, subject string) error
+	Send(ctx context.Context, email string, subject string, html string, text string, unsubscribeURL string) error
 }
 
 // NewEmailDeliveryJob creates an email dispatch job
-func NewEmailDeliveryJob(client *asynq.Client, data model.EmailPayload) error {
+func NewEmailDeliveryJob(client *asynq.Client, data model.EmailPayload) (err error) {
+	ctx, span := startEnqueueSpan(&data.TaskMeta, TypeEmailDelivery)
+	defer func() { tracing.End(span, err) }()
+
 	payload, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 	task := asynq.NewTask(TypeEmailDelivery, payload)
-	info, err := client.Enqueue(task, asynq.Queue("email"), asynq.MaxRetry(5))
+	info, err := client.EnqueueContext(ctx, task, asynq.Queue("email"), asynq.MaxRetry(5))
 	if err != nil {
 		return err
 	}
@@ -37,21 +45,60 @@ func NewEmailDeliveryJob(client *asynq.Client, data model.EmailPayload) error {
 
 // EmailProcessor implements asynq.Handler interface
 type EmailProcessor struct {
-	Dispatcher EmailDispatcher
+	Dispatcher  EmailDispatcher
+	Templates   *template.Registry
+	Preferences PreferenceStore
+	// used to defer emails during quiet hours
+	Client *asynq.Client
 }
 
 func (p *EmailProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
 	var payload model.EmailPayload
 	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
-		return fmt.Errorf("failed to unmarshal sms payload: %v: %w", err, asynq.SkipRetry)
+		return fmt.Errorf("failed to unmarshal email payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	// emails users can opt out of carry an unsubscribe link, transactional emails do not
+	var unsubscribeURL string
+	if payload.UserID != uuid.Nil && (payload.Type.Configurable() || payload.Type == model.DigestNotification) {
+		prefs, err := p.Preferences.GetPreferences(ctx, payload.UserID)
+		if err != nil {
+			return err
+		}
+		if !prefs.Allows(model.EmailChannel, payload.Type) {
+			log.Printf("skipped email disabled by preferences: user_id=%s type=%s\n", payload.UserID, payload.Type)
+			return nil
+		}
+		// digest users get the notification in their next digest
+		if payload.Type != model.DigestNotification && prefs.DeliveryMode == model.DigestDelivery {
+			return nil
+		}
+		if end, ok := prefs.QuietUntil(time.Now()); ok {
+			return deferTask(p.Client, t, EmailQueue, end)
+		}
+
+		if payload.Data == nil {
+			payload.Data = make(map[string]any)
+		}
+		unsubscribeURL = p.Preferences.UnsubscribeURL(payload.UserID)
+		payload.Data["unsubscribeUrl"] = unsubscribeURL
+	}
+
+	// a template that cannot be rendered will not render on retry either
+	email, err := p.Templates.Render(payload.TemplateID, payload.Locale, payload.Data)
+	if err != nil {
+		return fmt.Errorf("failed to render email template: %v: %w", err, asynq.SkipRetry)
 	}
 
 	// dispatch email
-	return p.Dispatcher.Send(payload.Email, payload.Message, payload.Subject)
+	return p.Dispatcher.Send(ctx, payload.Email, email.Subject, email.HTML, email.Text, unsubscribeURL)
 }
 
-func NewEmailProcessor(dispatcher EmailDispatcher) *EmailProcessor {
+func NewEmailProcessor(dispatcher EmailDispatcher, templates *template.Registry, preferences PreferenceStore, client *asynq.Client) *EmailProcessor {
 	return &EmailProcessor{
-		Dispatcher: dispatcher,
+		Dispatcher:  dispatcher,
+		Templates:   templates,
+		Preferences: preferences,
+		Client:      client,
 	}
 }
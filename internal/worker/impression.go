@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/hibiken/asynq"
+)
+
+// unique task type for the impression flush job
+const (
+	TypeFlushImpressions = "impressions:flush"
+)
+
+// the service that writes impressions from redis to postgres
+type ImpressionFlusher interface {
+	FlushImpressions(ctx context.Context) error
+}
+
+// NewFlushImpressionsTask creates the periodic task that flushes profile impressions to postgres
+func NewFlushImpressionsTask() *asynq.Task {
+	return asynq.NewTask(TypeFlushImpressions, nil)
+}
+
+// FlushImpressionsProcessor implements asynq.Handler interface
+type FlushImpressionsProcessor struct {
+	Impressions ImpressionFlusher
+}
+
+func (p *FlushImpressionsProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	return p.Impressions.FlushImpressions(ctx)
+}
+
+func NewFlushImpressionsProcessor(impressions ImpressionFlusher) *FlushImpressionsProcessor {
+	return &FlushImpressionsProcessor{
+		Impressions: impressions,
+	}
+}
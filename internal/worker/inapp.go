@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"konnect/internal/model"
+	"konnect/internal/tracing"
+	"log"
+
+	"github.com/hibiken/asynq"
+)
+
+// unique task type for the in-app notification job
+const (
+	TypeInAppDelivery = "inapp:delivery"
+)
+
+// the in-app notification store
+type InAppDispatcher interface {
+	Send(ctx context.Context, payload model.InAppPayload) error
+}
+
+// NewInAppDeliveryJob creates an in-app notification dispatch job
+func NewInAppDeliveryJob(client *asynq.Client, data model.InAppPayload) (err error) {
+	ctx, span := startEnqueueSpan(&data.TaskMeta, TypeInAppDelivery)
+	defer func() { tracing.End(span, err) }()
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	task := asynq.NewTask(TypeInAppDelivery, payload)
+	info, err := client.EnqueueContext(ctx, task, asynq.Queue(InAppQueue), asynq.MaxRetry(5))
+	if err != nil {
+		return err
+	}
+	log.Printf("enqueued in-app notification job: id=%s queue=%s\n", info.ID, info.Queue)
+	return nil
+}
+
+// InAppProcessor implements asynq.Handler interface
+type InAppProcessor struct {
+	Dispatcher InAppDispatcher
+}
+
+func (p *InAppProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.InAppPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal in-app payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	// persist notification for the user's notification center
+	return p.Dispatcher.Send(ctx, payload)
+}
+
+func NewInAppProcessor(dispatcher InAppDispatcher) *InAppProcessor {
+	return &InAppProcessor{
+		Dispatcher: dispatcher,
+	}
+}
@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"konnect/internal/logger"
+	"konnect/internal/model"
+	"konnect/internal/tracing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// NewTaskMeta returns the metadata of a task enqueued with ctx, e.g. the id of the request and the span enqueuing it
+func NewTaskMeta(ctx context.Context) model.TaskMeta {
+	return model.TaskMeta{RequestID: logger.RequestID(ctx), TraceContext: tracing.Inject(ctx)}
+}
+
+// LoggingMiddleware logs the outcome and duration of every task. The request id in the task payload is put in the
+// task context and the log entries, so a task can be traced back to the request that enqueued it
+func LoggingMiddleware(log *logger.Logger) asynq.MiddlewareFunc {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			// payloads without metadata leave it empty
+			var meta model.TaskMeta
+			if err := json.Unmarshal(t.Payload(), &meta); err == nil && meta.RequestID != "" {
+				ctx = logger.WithRequestID(ctx, meta.RequestID)
+			}
+			taskID, _ := asynq.GetTaskID(ctx)
+			retried, _ := asynq.GetRetryCount(ctx)
+
+			start := time.Now()
+			err := next.ProcessTask(ctx, t)
+			fields := []zap.Field{
+				zap.String("component", "worker"),
+				zap.String("type", t.Type()),
+				zap.String("task_id", taskID),
+				zap.Int("retried", retried),
+				zap.Duration("duration", time.Since(start)),
+				logger.RequestIDField(ctx),
+			}
+			if err != nil {
+				log.Error("task failed", append(fields, zap.Error(err))...)
+				return err
+			}
+			log.Info("task processed", fields...)
+			return nil
+		})
+	}
+}
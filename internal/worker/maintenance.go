@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"konnect/internal/cache"
+	"konnect/internal/database"
+	"konnect/internal/logger"
+	"konnect/internal/script"
+
+	"github.com/hibiken/asynq"
+)
+
+// unique task types for the cache maintenance jobs
+const (
+	TypePruneInterests = "cache:prune_interests"
+	TypeExpireFeeds    = "cache:expire_feeds"
+)
+
+// NewPruneInterestsTask creates the task that removes inactive users from the interest buckets
+func NewPruneInterestsTask() *asynq.Task {
+	return asynq.NewTask(TypePruneInterests, nil)
+}
+
+// NewExpireFeedsTask creates the task that sets an expiry on cached feeds without one
+func NewExpireFeedsTask() *asynq.Task {
+	return asynq.NewTask(TypeExpireFeeds, nil)
+}
+
+// PruneInterestsProcessor implements asynq.Handler interface
+type PruneInterestsProcessor struct {
+	db             *database.DB
+	interestsCache *cache.InterestCache
+	logger         *logger.Logger
+}
+
+func (p *PruneInterestsProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	return script.PruneInterestCache(p.db, p.interestsCache, p.logger)
+}
+
+func NewPruneInterestsProcessor(db *database.DB, interestsCache *cache.InterestCache, logger *logger.Logger) *PruneInterestsProcessor {
+	return &PruneInterestsProcessor{
+		db:             db,
+		interestsCache: interestsCache,
+		logger:         logger,
+	}
+}
+
+// ExpireFeedsProcessor implements asynq.Handler interface
+type ExpireFeedsProcessor struct {
+	feedCache *cache.FeedCache
+	logger    *logger.Logger
+}
+
+func (p *ExpireFeedsProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	return script.ExpireStaleFeeds(p.feedCache, p.logger)
+}
+
+func NewExpireFeedsProcessor(feedCache *cache.FeedCache, logger *logger.Logger) *ExpireFeedsProcessor {
+	return &ExpireFeedsProcessor{
+		feedCache: feedCache,
+		logger:    logger,
+	}
+}
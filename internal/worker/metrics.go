@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"konnect/internal/config"
+	"konnect/internal/metrics"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsMiddleware counts processed tasks by type and result and records how long they take
+func MetricsMiddleware() asynq.MiddlewareFunc {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			start := time.Now()
+			err := next.ProcessTask(ctx, t)
+
+			metrics.TaskDuration.WithLabelValues(t.Type()).Observe(time.Since(start).Seconds())
+			metrics.TasksProcessed.WithLabelValues(t.Type(), metrics.Result(err)).Inc()
+			return err
+		})
+	}
+}
+
+var (
+	queueSizeDesc = prometheus.NewDesc(
+		"konnect_worker_queue_tasks",
+		"Tasks in a worker queue by state.",
+		[]string{"queue", "state"}, nil,
+	)
+	queueLatencyDesc = prometheus.NewDesc(
+		"konnect_worker_queue_latency_seconds",
+		"Time the oldest pending task of a worker queue has waited.",
+		[]string{"queue"}, nil,
+	)
+)
+
+// QueueCollector reports the depth of the worker queues, read from the broker on every scrape
+type QueueCollector struct {
+	inspector *asynq.Inspector
+}
+
+// NewQueueCollector connects to the broker to inspect the queues. The caller must close it on shutdown
+func NewQueueCollector(cfg *config.Config) *QueueCollector {
+	return &QueueCollector{
+		inspector: asynq.NewInspector(asynq.RedisClientOpt{Addr: cfg.RedisAddr, Password: cfg.RedisPassword}),
+	}
+}
+
+func (c *QueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueSizeDesc
+	ch <- queueLatencyDesc
+}
+
+// Collect reports every queue in Queues. Queues that cannot be read, e.g. before their first task, are left out
+func (c *QueueCollector) Collect(ch chan<- prometheus.Metric) {
+	for queue := range Queues {
+		info, err := c.inspector.GetQueueInfo(queue)
+		if err != nil {
+			continue
+		}
+
+		states := map[string]int{
+			"pending":   info.Pending,
+			"active":    info.Active,
+			"scheduled": info.Scheduled,
+			"retry":     info.Retry,
+			"archived":  info.Archived,
+			"completed": info.Completed,
+		}
+		for state, size := range states {
+			ch <- prometheus.MustNewConstMetric(queueSizeDesc, prometheus.GaugeValue, float64(size), queue, state)
+		}
+		ch <- prometheus.MustNewConstMetric(queueLatencyDesc, prometheus.GaugeValue, info.Latency.Seconds(), queue)
+	}
+}
+
+func (c *QueueCollector) Close() error {
+	return c.inspector.Close()
+}
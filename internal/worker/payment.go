@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"konnect/internal/model"
+	"konnect/internal/tracing"
+	"log"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// unique task type for the payment verification job
+const (
+	TypeVerifyPayment = "billing:verify_payment"
+)
+
+// waits between verifications of a pending payment, about an hour in total. Payments still pending afterwards are left
+// to the charge webhook
+var paymentVerificationDelays = []time.Duration{
+	15 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	2 * time.Minute,
+	5 * time.Minute,
+	10 * time.Minute,
+	15 * time.Minute,
+	30 * time.Minute,
+}
+
+// the service that checks pending payments with the payment provider
+type PaymentVerifier interface {
+	// VerifyPendingPayment reports whether the payment reached a final status
+	VerifyPendingPayment(ctx context.Context, reference string) (bool, error)
+}
+
+// NewPaymentVerificationJob schedules the next verification of a pending payment, waiting longer after each attempt
+func NewPaymentVerificationJob(client *asynq.Client, data model.PaymentVerificationPayload) (err error) {
+	if data.Attempt >= len(paymentVerificationDelays) {
+		return nil
+	}
+	ctx, span := startEnqueueSpan(&data.TaskMeta, TypeVerifyPayment)
+	defer func() { tracing.End(span, err) }()
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	task := asynq.NewTask(TypeVerifyPayment, payload)
+	info, err := client.EnqueueContext(ctx, task, asynq.Queue(DefaultQueue), asynq.MaxRetry(3), asynq.ProcessIn(paymentVerificationDelays[data.Attempt]))
+	if err != nil {
+		return err
+	}
+	log.Printf("enqueued payment verification job: id=%s queue=%s\n", info.ID, info.Queue)
+	return nil
+}
+
+// PaymentVerificationProcessor implements asynq.Handler interface
+type PaymentVerificationProcessor struct {
+	Payments PaymentVerifier
+	// used to schedule the next verification
+	Client *asynq.Client
+}
+
+func (p *PaymentVerificationProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.PaymentVerificationPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payment verification payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	done, err := p.Payments.VerifyPendingPayment(ctx, payload.Reference)
+	if err != nil {
+		return err
+	}
+	if done {
+		return nil
+	}
+
+	// the next verification continues the logs and trace of this one
+	payload.TaskMeta = NewTaskMeta(ctx)
+	payload.Attempt++
+	return NewPaymentVerificationJob(p.Client, payload)
+}
+
+func NewPaymentVerificationProcessor(payments PaymentVerifier, client *asynq.Client) *PaymentVerificationProcessor {
+	return &PaymentVerificationProcessor{
+		Payments: payments,
+		Client:   client,
+	}
+}
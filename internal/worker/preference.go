@@ -0,0 +1,28 @@
+package worker
+
+import (
+	"context"
+	"konnect/internal/model"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+// the notification preference store consulted before dispatching user notifications
+type PreferenceStore interface {
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*model.NotificationPreference, error)
+	// one-click link that stops notification emails
+	UnsubscribeURL(userID uuid.UUID) string
+}
+
+// deferTask enqueues a copy of the task to be processed when the recipient's quiet hours end
+func deferTask(client *asynq.Client, t *asynq.Task, queue string, at time.Time) error {
+	info, err := client.Enqueue(asynq.NewTask(t.Type(), t.Payload()), asynq.Queue(queue), asynq.MaxRetry(5), asynq.ProcessAt(at))
+	if err != nil {
+		return err
+	}
+	log.Printf("deferred %s job until quiet hours end: id=%s process_at=%s\n", t.Type(), info.ID, at.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,118 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"konnect/internal/cache"
+	"konnect/internal/database"
+	"konnect/internal/logger"
+	"konnect/internal/metrics"
+	"konnect/internal/model"
+	"konnect/internal/tracing"
+	"log"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// unique task type for the profile cache sync job
+const (
+	TypeSyncProfile = "cache:sync_profile"
+)
+
+// NewProfileSyncJob creates a job that syncs a user's profile into the interest cache and location index
+func NewProfileSyncJob(client *asynq.Client, data model.ProfileSyncPayload) (err error) {
+	ctx, span := startEnqueueSpan(&data.TaskMeta, TypeSyncProfile)
+	defer func() { tracing.End(span, err) }()
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	task := asynq.NewTask(TypeSyncProfile, payload)
+	info, err := client.EnqueueContext(ctx, task, asynq.Queue(CriticalQueue), asynq.MaxRetry(10))
+	if err != nil {
+		return err
+	}
+	log.Printf("enqueued profile sync job: id=%s queue=%s\n", info.ID, info.Queue)
+	return nil
+}
+
+// ProfileSyncProcessor implements asynq.Handler interface
+type ProfileSyncProcessor struct {
+	db             *database.DB
+	interestsCache *cache.InterestCache
+	geoCache       *cache.GeoCache
+	stats          *cache.SyncStatsCache
+	logger         *logger.Logger
+}
+
+// ProcessTask reads the profile from postgres instead of trusting the payload, so retries and out of order jobs converge on the current state
+func (p *ProfileSyncProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.ProfileSyncPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal profile sync payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	var interests []string
+	var found bool
+	var profile model.Profile
+	err := p.db.WithContext(ctx).Select("user_id", "interests", "latitude", "longitude").Where("user_id = ?", payload.UserID).Take(&profile).Error
+	switch {
+	case err == nil:
+		interests, found = profile.Interests, true
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// deleted profiles are evicted from the cache and location index
+	default:
+		p.recordFailure(ctx, err)
+		return err
+	}
+
+	result, err := p.interestsCache.SyncUserInterests(ctx, payload.UserID.String(), interests)
+	if err != nil {
+		p.recordFailure(ctx, err)
+		return err
+	}
+
+	if found {
+		err = p.geoCache.SetLocation(ctx, payload.UserID.String(), profile.Latitude, profile.Longitude)
+	} else {
+		err = p.geoCache.RemoveLocation(ctx, payload.UserID.String())
+	}
+	if err != nil {
+		p.recordFailure(ctx, err)
+		return err
+	}
+
+	lag := time.Since(payload.ChangedAt)
+	p.stats.RecordSync(ctx, lag)
+	metrics.CacheSyncsTotal.WithLabelValues(metrics.Result(nil)).Inc()
+	metrics.CacheSyncLag.Observe(lag.Seconds())
+	p.logger.Debug("profile synced to interest cache",
+		zap.String("user_id", payload.UserID.String()),
+		zap.Int("added", result.Added),
+		zap.Int("removed", result.Removed),
+		zap.Duration("lag", lag),
+	)
+	return nil
+}
+
+// recordFailure records a failed sync attempt for the admin stats and the metrics
+func (p *ProfileSyncProcessor) recordFailure(ctx context.Context, err error) {
+	p.stats.RecordFailure(ctx, err)
+	metrics.CacheSyncsTotal.WithLabelValues(metrics.Result(err)).Inc()
+}
+
+func NewProfileSyncProcessor(db *database.DB, interestsCache *cache.InterestCache, geoCache *cache.GeoCache, stats *cache.SyncStatsCache, logger *logger.Logger) *ProfileSyncProcessor {
+	return &ProfileSyncProcessor{
+		db:             db,
+		interestsCache: interestsCache,
+		geoCache:       geoCache,
+		stats:          stats,
+		logger:         logger,
+	}
+}
@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"konnect/internal/model"
+	"konnect/internal/tracing"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+// unique task type for the push notification job
+const (
+	TypePushDelivery = "push:delivery"
+)
+
+// ErrInvalidPushToken is returned by a push dispatcher when the provider rejects a device token for good
+var ErrInvalidPushToken = errors.New("invalid push token")
+
+// the push notification sender
+type PushDispatcher interface {
+	Send(ctx context.Context, token string, title string, body string, data map[string]string) error
+}
+
+// the device token store used to resolve and prune a user's devices
+type DeviceStore interface {
+	GetUserDeviceTokens(ctx context.Context, userID uuid.UUID) ([]string, error)
+	DeleteDeviceTokens(ctx context.Context, tokens []string) error
+}
+
+// NewPushDeliveryJob creates a push notification dispatch job
+func NewPushDeliveryJob(client *asynq.Client, data model.PushPayload) (err error) {
+	ctx, span := startEnqueueSpan(&data.TaskMeta, TypePushDelivery)
+	defer func() { tracing.End(span, err) }()
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	task := asynq.NewTask(TypePushDelivery, payload)
+	info, err := client.EnqueueContext(ctx, task, asynq.Queue(PushQueue), asynq.MaxRetry(5))
+	if err != nil {
+		return err
+	}
+	log.Printf("enqueued push job: id=%s queue=%s\n", info.ID, info.Queue)
+	return nil
+}
+
+// PushProcessor implements asynq.Handler interface
+type PushProcessor struct {
+	Dispatcher  PushDispatcher
+	Devices     DeviceStore
+	Preferences PreferenceStore
+	// used to defer pushes during quiet hours
+	Client *asynq.Client
+}
+
+func (p *PushProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.PushPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal push payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	if payload.Type.Configurable() {
+		prefs, err := p.Preferences.GetPreferences(ctx, payload.UserID)
+		if err != nil {
+			return err
+		}
+		if !prefs.Allows(model.PushChannel, payload.Type) {
+			log.Printf("skipped push disabled by preferences: user_id=%s type=%s\n", payload.UserID, payload.Type)
+			return nil
+		}
+		if end, ok := prefs.QuietUntil(time.Now()); ok {
+			return deferTask(p.Client, t, PushQueue, end)
+		}
+	}
+
+	tokens, err := p.Devices.GetUserDeviceTokens(ctx, payload.UserID)
+	if err != nil {
+		return err
+	}
+
+	// deliver to every registered device of the user, collecting tokens rejected by the provider
+	var (
+		invalid   []string
+		delivered int
+		lastErr   error
+	)
+	for _, token := range tokens {
+		err := p.Dispatcher.Send(ctx, token, payload.Title, payload.Body, payload.Data)
+		switch {
+		case err == nil:
+			delivered++
+		case errors.Is(err, ErrInvalidPushToken):
+			invalid = append(invalid, token)
+		default:
+			lastErr = err
+		}
+	}
+
+	if len(invalid) > 0 {
+		if err := p.Devices.DeleteDeviceTokens(ctx, invalid); err != nil {
+			log.Printf("failed to prune invalid push tokens: user_id=%s err=%v\n", payload.UserID, err)
+		}
+	}
+
+	// retry only when no device received the notification to avoid duplicate pushes
+	if delivered == 0 && lastErr != nil {
+		return lastErr
+	}
+	return nil
+}
+
+func NewPushProcessor(dispatcher PushDispatcher, devices DeviceStore, preferences PreferenceStore, client *asynq.Client) *PushProcessor {
+	return &PushProcessor{
+		Dispatcher:  dispatcher,
+		Devices:     devices,
+		Preferences: preferences,
+		Client:      client,
+	}
+}
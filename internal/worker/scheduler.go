@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"errors"
+	"konnect/internal/config"
+	"konnect/internal/model"
+	"log"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// PeriodicTask is a task enqueued by the scheduler on a cron schedule
+type PeriodicTask struct {
+	// standard cron spec evaluated in utc, e.g. "0 8 * * *"
+	Cronspec string
+	Task     *asynq.Task
+	Opts     []asynq.Option
+}
+
+// PeriodicTasks returns the tasks the worker runs on a schedule
+func PeriodicTasks(cfg *config.Config) ([]PeriodicTask, error) {
+	dailyDigest, err := NewDigestTask(model.DailyDigest)
+	if err != nil {
+		return nil, err
+	}
+	weeklyDigest, err := NewDigestTask(model.WeeklyDigest)
+	if err != nil {
+		return nil, err
+	}
+	seedCache, err := NewCacheSeedingTask(false)
+	if err != nil {
+		return nil, err
+	}
+	reconcileCache, err := NewCacheSeedingTask(true)
+	if err != nil {
+		return nil, err
+	}
+
+	// unique locks keep every worker replica's scheduler from enqueuing the same run
+	return []PeriodicTask{
+		{Cronspec: cfg.CacheSeedCron, Task: seedCache, Opts: []asynq.Option{asynq.Queue(CriticalQueue), asynq.MaxRetry(5), asynq.Unique(10 * time.Minute)}},
+		{Cronspec: cfg.CacheReconcileCron, Task: reconcileCache, Opts: []asynq.Option{asynq.Queue(LowQueue), asynq.MaxRetry(3), asynq.Unique(time.Hour)}},
+		{Cronspec: cfg.GeoSeedCron, Task: NewGeoSeedingTask(), Opts: []asynq.Option{asynq.Queue(LowQueue), asynq.MaxRetry(3), asynq.Unique(30 * time.Minute)}},
+		{Cronspec: cfg.BoostExpiryCron, Task: NewExpireBoostsTask(), Opts: []asynq.Option{asynq.Queue(DefaultQueue), asynq.MaxRetry(1), asynq.Unique(time.Minute)}},
+		{Cronspec: cfg.ImpressionCron, Task: NewFlushImpressionsTask(), Opts: []asynq.Option{asynq.Queue(LowQueue), asynq.MaxRetry(3), asynq.Unique(10 * time.Minute)}},
+		{Cronspec: cfg.InterestPruneCron, Task: NewPruneInterestsTask(), Opts: []asynq.Option{asynq.Queue(LowQueue), asynq.MaxRetry(3), asynq.Unique(30 * time.Minute)}},
+		{Cronspec: cfg.FeedExpiryCron, Task: NewExpireFeedsTask(), Opts: []asynq.Option{asynq.Queue(LowQueue), asynq.MaxRetry(3), asynq.Unique(10 * time.Minute)}},
+		{Cronspec: cfg.DigestDailyCron, Task: dailyDigest, Opts: []asynq.Option{asynq.Queue(LowQueue), asynq.MaxRetry(3), asynq.Unique(time.Hour)}},
+		{Cronspec: cfg.DigestWeeklyCron, Task: weeklyDigest, Opts: []asynq.Option{asynq.Queue(LowQueue), asynq.MaxRetry(3), asynq.Unique(time.Hour)}},
+	}, nil
+}
+
+// NewScheduler creates a scheduler with the periodic tasks registered. Every worker runs a scheduler,
+// periodic tasks must therefore be unique to be enqueued once per run
+func NewScheduler(cfg *config.Config, tasks []PeriodicTask) (*asynq.Scheduler, error) {
+	scheduler := asynq.NewScheduler(
+		asynq.RedisClientOpt{Addr: cfg.RedisAddr, Password: cfg.RedisPassword},
+		&asynq.SchedulerOpts{
+			Location: time.UTC,
+			PostEnqueueFunc: func(info *asynq.TaskInfo, err error) {
+				// another replica enqueued this run
+				if errors.Is(err, asynq.ErrDuplicateTask) {
+					return
+				}
+				if err != nil {
+					log.Printf("failed to enqueue periodic task: err=%v\n", err)
+					return
+				}
+				log.Printf("enqueued periodic %s job: id=%s queue=%s\n", info.Type, info.ID, info.Queue)
+			},
+		},
+	)
+
+	for _, task := range tasks {
+		if _, err := scheduler.Register(task.Cronspec, task.Task, task.Opts...); err != nil {
+			return nil, err
+		}
+	}
+	return scheduler, nil
+}
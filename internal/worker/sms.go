@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"konnect/internal/model"
+	"konnect/internal/tracing"
 	"log"
 
 	"github.com/hibiken/asynq"
@@ -17,17 +18,20 @@ const (
 
 // the sms sender
 type SMSDispatcher interface {
-	Send(phoneNumbers []string, message string) error
+	Send(ctx context.Context, phoneNumbers []string, message string) error
 }
 
-// NewSMSDeliveryJob creates an email dispatch job
-func NewSMSDeliveryJob(client *asynq.Client, data model.SMSPayload) error {
+// NewSMSDeliveryJob creates an sms dispatch job
+func NewSMSDeliveryJob(client *asynq.Client, data model.SMSPayload) (err error) {
+	ctx, span := startEnqueueSpan(&data.TaskMeta, TypeSMSDelivery)
+	defer func() { tracing.End(span, err) }()
+
 	payload, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 	task := asynq.NewTask(TypeSMSDelivery, payload)
-	info, err := client.Enqueue(task, asynq.Queue("sms"), asynq.MaxRetry(5))
+	info, err := client.EnqueueContext(ctx, task, asynq.Queue("sms"), asynq.MaxRetry(5))
 	if err != nil {
 		return err
 	}
@@ -47,7 +51,7 @@ func (p *SMSProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
 	}
 
 	// dispatch sms
-	return p.Dispatcher.Send(payload.PhoneNumbers, payload.Message)
+	return p.Dispatcher.Send(ctx, payload.PhoneNumbers, payload.Message)
 }
 
 func NewSMSProcessor(dispatcher SMSDispatcher) *SMSProcessor {
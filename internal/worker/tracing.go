@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"konnect/internal/model"
+	"konnect/internal/tracing"
+
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware processes every task in a span continuing the trace of the span that enqueued it
+func TracingMiddleware() asynq.MiddlewareFunc {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			// payloads without metadata start a new trace
+			var meta model.TaskMeta
+			if err := json.Unmarshal(t.Payload(), &meta); err == nil {
+				ctx = tracing.Extract(ctx, meta.TraceContext)
+			}
+			taskID, _ := asynq.GetTaskID(ctx)
+			queue, _ := asynq.GetQueueName(ctx)
+			retried, _ := asynq.GetRetryCount(ctx)
+
+			ctx, span := tracing.Start(ctx, t.Type()+" process",
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					attribute.String("messaging.system", "asynq"),
+					attribute.String("messaging.operation.type", "process"),
+					attribute.String("messaging.destination.name", queue),
+					attribute.String("messaging.message.id", taskID),
+					attribute.Int("asynq.retried", retried),
+				),
+			)
+			err := next.ProcessTask(ctx, t)
+			tracing.End(span, err)
+			return err
+		})
+	}
+}
+
+// startEnqueueSpan starts the span of enqueuing a task as a child of the span in the task metadata, and points the
+// metadata at it so the task is processed in a child span. Tasks enqueued outside of a trace get a span that records
+// nothing
+func startEnqueueSpan(meta *model.TaskMeta, taskType string) (context.Context, trace.Span) {
+	ctx := tracing.Extract(context.Background(), meta.TraceContext)
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+
+	ctx, span := tracing.Start(ctx, taskType+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "asynq"),
+			attribute.String("messaging.operation.type", "send"),
+		),
+	)
+	meta.TraceContext = tracing.Inject(ctx)
+	return ctx, span
+}
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"konnect/internal/model"
+	"konnect/internal/tracing"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+// unique task type for the webhook event job
+const (
+	TypeWebhookEvent = "webhook:event"
+)
+
+// the service that applies stored webhook events
+type WebhookEventHandler interface {
+	ProcessWebhookEvent(ctx context.Context, id uuid.UUID) error
+}
+
+// NewWebhookEventJob enqueues the processing of a stored webhook event. Every delivery of an event that is not processed
+// yet enqueues a job, including redeliveries of events whose jobs ran out of retries. Jobs skip processed events
+func NewWebhookEventJob(client *asynq.Client, data model.WebhookEventPayload) (err error) {
+	ctx, span := startEnqueueSpan(&data.TaskMeta, TypeWebhookEvent)
+	defer func() { tracing.End(span, err) }()
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	task := asynq.NewTask(TypeWebhookEvent, payload)
+	info, err := client.EnqueueContext(ctx, task, asynq.Queue(CriticalQueue), asynq.MaxRetry(10))
+	if err != nil {
+		return err
+	}
+	log.Printf("enqueued webhook event job: id=%s queue=%s\n", info.ID, info.Queue)
+	return nil
+}
+
+// WebhookEventProcessor implements asynq.Handler interface
+type WebhookEventProcessor struct {
+	Events WebhookEventHandler
+}
+
+func (p *WebhookEventProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.WebhookEventPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal webhook event payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	return p.Events.ProcessWebhookEvent(ctx, payload.EventID)
+}
+
+func NewWebhookEventProcessor(events WebhookEventHandler) *WebhookEventProcessor {
+	return &WebhookEventProcessor{
+		Events: events,
+	}
+}